@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/yourusername/compose-validator/internal/config"
 	"github.com/yourusername/compose-validator/internal/fixer"
+	"github.com/yourusername/compose-validator/internal/inference"
 	"github.com/yourusername/compose-validator/internal/parser"
 	"github.com/yourusername/compose-validator/internal/validator"
 )
@@ -25,6 +27,22 @@ var (
 	configPath string
 	checkOrder bool
 	checkAlpha bool
+	strictMode bool
+	setFlags   []string
+
+	// Settings from the environment and flags, applied on top of config files
+	settings []config.Setting
+
+	// Init flags
+	initOutput string
+	initForce  bool
+
+	// Print-config flags
+	printService string
+	printFormat  string
+
+	// Configurations resolved per directory
+	dirConfigs = make(map[string]*config.Config)
 )
 
 func main() {
@@ -44,6 +62,8 @@ Ensures field ordering and alphabetization of environment variables, volumes, an
 	rootCmd.Flags().StringVar(&configPath, "config", "", "Path to configuration file")
 	rootCmd.Flags().BoolVar(&checkOrder, "check-order-only", false, "Only check field order")
 	rootCmd.Flags().BoolVar(&checkAlpha, "check-alphabetization-only", false, "Only check alphabetization")
+	rootCmd.Flags().BoolVar(&strictMode, "strict", false, "Report fields not listed in field_order")
+	rootCmd.Flags().StringArrayVar(&setFlags, "set", nil, "Set a config key, e.g. alphabetization.volumes=false (repeatable)")
 
 	versionCmd := &cobra.Command{
 		Use:   "version",
@@ -53,7 +73,52 @@ Ensures field ordering and alphabetization of environment variables, volumes, an
 		},
 	}
 
+	initCmd := &cobra.Command{
+		Use:   "init [paths...]",
+		Short: "Generate a configuration from existing compose files",
+		Long: `Scan compose files and write a configuration with the field order and
+alphabetization conventions they already follow, chosen so that the fewest
+files fail. Directories are searched recursively; the default is the current
+directory.`,
+		RunE: runInit,
+	}
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", ".compose-validator.yaml", "File to write the configuration to (- for stdout)")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing configuration file")
+
+	printConfigCmd := &cobra.Command{
+		Use:   "print-config [file]",
+		Short: "Print the effective configuration and where it comes from",
+		Long: `Print the config files the configuration was loaded from and the settings in
+effect. With a compose file, the configuration is resolved for that file,
+with matching overrides blocks applied; with --service, the service_overrides
+for that service are applied too.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runPrintConfig,
+	}
+	printConfigCmd.Flags().StringVar(&configPath, "config", "", "Path to configuration file")
+	printConfigCmd.Flags().StringVar(&printService, "service", "", "Apply the service_overrides for this service")
+	printConfigCmd.Flags().StringVar(&printFormat, "format", "yaml", "Output format: yaml or json")
+	printConfigCmd.Flags().BoolVar(&strictMode, "strict", false, "Report fields not listed in field_order")
+	printConfigCmd.Flags().StringArrayVar(&setFlags, "set", nil, "Set a config key, e.g. alphabetization.volumes=false (repeatable)")
+
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON schema of the configuration file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := config.JSONSchema()
+			if err != nil {
+				return err
+			}
+			_, err = os.Stdout.Write(data)
+			return err
+		},
+	}
+
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(printConfigCmd)
+	rootCmd.AddCommand(schemaCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -65,32 +130,28 @@ func run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no files specified")
 	}
 
-	// Load configuration
-	var cfg *config.Config
-	var err error
-
+	// Load the --config file; otherwise each file's configuration is found
+	// from its own directory
+	var explicit *config.Config
 	if configPath != "" {
-		cfg, err = config.LoadFromFile(configPath)
-	} else {
-		cfg, err = config.Load()
+		var err error
+		explicit, err = config.LoadFromFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
 	}
 
-	if err != nil {
+	if err := loadSettings(cmd); err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	if verbose {
-		color.Blue("Loaded configuration")
-		fmt.Printf("Field order: %v\n", cfg.FieldOrder)
-		fmt.Printf("Alphabetization: env=%v, volumes=%v, labels=%v\n",
-			cfg.Alphabetization.Environment,
-			cfg.Alphabetization.Volumes,
-			cfg.Alphabetization.Labels)
-	}
-
 	// Process files
 	allValid := true
 	totalViolations := 0
+	checkedFiles := make([]string, 0)
+	checkedConfigs := make([]*config.Config, 0)
+	checkedServices := make([]config.ServiceRef, 0)
+	usedConfigs := make([]*config.Config, 0)
 
 	for _, pattern := range args {
 		files, err := filepath.Glob(pattern)
@@ -104,27 +165,66 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 
 		for _, file := range files {
-			if cfg.IsExcluded(file) {
+			fileCfg, err := configFor(file, explicit)
+			if err != nil {
+				return fmt.Errorf("failed to load configuration for %s: %w", file, err)
+			}
+
+			if fileCfg.IsExcluded(file) {
 				if verbose {
 					color.Yellow("Skipping excluded file: %s", file)
 				}
 				continue
 			}
 
-			result, err := processFile(file, cfg)
+			if !containsConfig(usedConfigs, fileCfg) {
+				usedConfigs = append(usedConfigs, fileCfg)
+				if verbose {
+					printConfigSummary(file, fileCfg)
+				}
+			}
+
+			result, err := processFile(file, fileCfg, &checkedServices)
 			if err != nil {
 				color.Red("Error processing %s: %v", file, err)
 				allValid = false
 				continue
 			}
+			checkedFiles = append(checkedFiles, file)
+			checkedConfigs = append(checkedConfigs, fileCfg)
 
 			if !result.Valid {
 				allValid = false
-				totalViolations += len(result.Violations)
+				totalViolations += result.Errors()
 			}
 		}
 	}
 
+	// Warn about overrides for services that none of the files define
+	if len(checkedFiles) > 0 {
+		warned := make(map[string]bool)
+		for _, used := range usedConfigs {
+			for _, name := range used.UnmatchedOverrides(checkedServices) {
+				if !warned[name] {
+					warned[name] = true
+					color.Yellow("Warning: service_overrides entry '%s' does not match any service", name)
+				}
+			}
+		}
+	}
+
+	// Check rules spanning all files of this run
+	if len(checkedFiles) > 1 {
+		violations, err := processAcrossFiles(checkedFiles, checkedConfigs)
+		if err != nil {
+			return err
+		}
+		if violations > 0 {
+			allValid = false
+			totalViolations += violations
+		}
+	}
+
 	if allValid {
 		color.Green("✓ All files are valid!")
 		return nil
@@ -138,11 +238,195 @@ func run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func processFile(path string, cfg *config.Config) (*validator.ValidationResult, error) {
+// runInit writes a configuration inferred from the compose files in args
+func runInit(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	if initOutput != "-" && !initForce {
+		if _, err := os.Stat(initOutput); err == nil {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", initOutput)
+		}
+	}
+
+	paths, err := inference.FindComposeFiles(args)
+	if err != nil {
+		return err
+	}
+
+	files := make([]*parser.ComposeFile, 0, len(paths))
+	for _, path := range paths {
+		file, err := parser.ParseFile(path)
+		if err != nil {
+			color.Yellow("Warning: skipping %s: %v", path, err)
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no compose files found")
+	}
+
+	result, err := inference.Infer(files)
+	if err != nil {
+		return fmt.Errorf("failed to infer configuration: %w", err)
+	}
+
+	if initOutput == "-" {
+		_, err := os.Stdout.Write(result.Render())
+		return err
+	}
+	if err := os.WriteFile(initOutput, result.Render(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", initOutput, err)
+	}
+
+	color.Green("✓ Wrote %s from %d file(s) with %d service(s)", initOutput, result.Files, result.Services)
+	if result.Failing > 0 {
+		fmt.Printf("%d file(s) do not follow the field order yet; run with --fix to reorder them\n", result.Failing)
+	}
+	return nil
+}
+
+// runPrintConfig prints the configuration for the compose file in args, or
+// for the current directory
+func runPrintConfig(cmd *cobra.Command, args []string) error {
+	var explicit *config.Config
+	var err error
+	if configPath != "" {
+		explicit, err = config.LoadFromFile(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
+	if err := loadSettings(cmd); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	var cfg *config.Config
+	ref := config.ServiceRef{Name: printService}
+	if len(args) == 0 {
+		cfg = explicit
+		if cfg == nil {
+			cfg, err = config.Load()
+			if err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+		}
+		cfg, err = cfg.Apply(settings)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+	} else {
+		cfg, err = configFor(args[0], explicit)
+		if err != nil {
+			return fmt.Errorf("failed to load configuration for %s: %w", args[0], err)
+		}
+
+		// The overrides blocks are resolved for this file
+		effective := *cfg
+		effective.Overrides = nil
+		cfg = &effective
+
+		if printService != "" {
+			file, err := parser.ParseFile(args[0])
+			if err != nil {
+				return err
+			}
+			service, ok := file.GetServices()[printService]
+			if !ok {
+				return fmt.Errorf("service %s not found in %s", printService, args[0])
+			}
+			ref = service.Ref()
+		}
+	}
+	if printService != "" {
+		cfg = cfg.ForService(ref)
+	}
+
+	data, err := cfg.Encode(printFormat)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
+
+// loadSettings reads the settings from COMPOSE_VALIDATOR_* environment
+// variables and the --strict and --set flags, in that order
+func loadSettings(cmd *cobra.Command) error {
+	var err error
+	settings, err = config.EnvSettings(os.Environ())
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("strict") {
+		settings = append(settings, config.Setting{Key: "strict", Value: fmt.Sprint(strictMode), Source: "--strict"})
+	}
+	for _, flag := range setFlags {
+		setting, err := config.ParseSetting(flag)
+		if err != nil {
+			return err
+		}
+		settings = append(settings, setting)
+	}
+	return nil
+}
+
+// configFor returns the configuration for a file: the --config file if one
+// was given, otherwise the configs found from the file's directory upward,
+// with the overrides blocks matching the file applied, and then the settings
+// from the environment and flags
+func configFor(path string, explicit *config.Config) (*config.Config, error) {
+	if configPath != "" {
+		cfg, err := explicit.ForFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.Apply(settings)
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok := dirConfigs[dir]
+	if !ok {
+		cfg, err = config.LoadForDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		dirConfigs[dir] = cfg
+	}
+
+	cfg, err = cfg.ForFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Apply(settings)
+}
+
+// containsConfig checks if a configuration is in the list
+func containsConfig(configs []*config.Config, cfg *config.Config) bool {
+	for _, c := range configs {
+		if c == cfg {
+			return true
+		}
+	}
+	return false
+}
+
+// processFile validates and optionally fixes a file, adding its services to
+// services
+func processFile(path string, cfg *config.Config, services *[]config.ServiceRef) (*validator.ValidationResult, error) {
 	file, err := parser.ParseFile(path)
 	if err != nil {
 		return nil, err
 	}
+	for _, service := range file.GetServices() {
+		*services = append(*services, service.Ref())
+	}
 
 	result, err := validator.Validate(file, cfg)
 	if err != nil {
@@ -172,9 +456,11 @@ func processFile(path string, cfg *config.Config) (*validator.ValidationResult,
 				return nil, err
 			}
 		}
-	} else if !result.Valid {
-		// Print violations
-		color.Red("✗ %s:", path)
+	}
+
+	if len(result.Violations) > 0 {
+		// Print violations, including those left after fixing
+		printFileHeader(result)
 		for _, v := range result.Violations {
 			printViolation(v, cfg)
 		}
@@ -185,7 +471,61 @@ func processFile(path string, cfg *config.Config) (*validator.ValidationResult,
 	return result, nil
 }
 
+// processAcrossFiles validates rules comparing services between files, each
+// with its own configuration, and returns the number of violations found
+func processAcrossFiles(paths []string, configs []*config.Config) (int, error) {
+	files := make([]*parser.ComposeFile, 0, len(paths))
+	for _, path := range paths {
+		file, err := parser.ParseFile(path)
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, file)
+	}
+
+	total := 0
+	for i, result := range validator.ValidateAcrossFiles(files, configs) {
+		if len(result.Violations) == 0 {
+			continue
+		}
+		printFileHeader(result)
+		for _, v := range result.Violations {
+			printViolation(v, configs[i])
+		}
+		total += result.Errors()
+	}
+
+	return total, nil
+}
+
+// printConfigSummary prints the configuration first used for a file
+func printConfigSummary(path string, cfg *config.Config) {
+	color.Blue("Configuration for %s", path)
+	if len(cfg.Sources) > 0 {
+		fmt.Printf("Sources: %s\n", strings.Join(cfg.Sources, ", "))
+	}
+	fmt.Printf("Field order: %v\n", cfg.FieldOrder)
+	fmt.Printf("Alphabetization: env=%v, volumes=%v, labels=%v\n",
+		cfg.Alphabetization.Environment,
+		cfg.Alphabetization.Volumes,
+		cfg.Alphabetization.Labels)
+}
+
+// printFileHeader prints the file name before its violations, as a failure
+// or, when all of them are warnings, as a warning
+func printFileHeader(result *validator.ValidationResult) {
+	if result.Valid {
+		color.Yellow("! %s:", result.File)
+		return
+	}
+	color.Red("✗ %s:", result.File)
+}
+
 func printViolation(v validator.Violation, cfg *config.Config) {
+	if v.Severity == "warning" {
+		v.Message = "warning: " + v.Message
+	}
+
 	switch v.Type {
 	case "order":
 		fmt.Printf("  Service '%s': %s\n", v.Service, v.Message)
@@ -203,5 +543,16 @@ func printViolation(v validator.Violation, cfg *config.Config) {
 		if v.Line > 0 {
 			fmt.Printf("    Line: %d\n", v.Line)
 		}
+
+	default:
+		// File-level violations, such as duplicate top-level keys, have no service
+		if v.Service == "" {
+			fmt.Printf("  %s\n", v.Message)
+		} else {
+			fmt.Printf("  Service '%s': %s\n", v.Service, v.Message)
+		}
+		if v.Line > 0 {
+			fmt.Printf("    Line: %d\n", v.Line)
+		}
 	}
 }
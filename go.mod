@@ -5,6 +5,7 @@ go 1.22
 require (
 	github.com/fatih/color v1.18.0
 	github.com/goccy/go-yaml v1.15.13
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	github.com/spf13/cobra v1.10.2
 )
 
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/parser"
+	"github.com/goccy/go-yaml/token"
+)
+
+// checkFieldOrders reports field_order lists that decoding accepts but that
+// are likely mistakes: fields listed twice and unknown keys in groups. Errors
+// carry the line and column of the entry, as decoding errors do.
+func checkFieldOrders(data []byte) error {
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range file.Docs {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		if err := checkFieldOrder(mappingChild(doc.Body, "field_order"), "field_order"); err != nil {
+			return err
+		}
+		overrides, _ := mappingChild(doc.Body, "service_overrides").(*ast.MappingNode)
+		if overrides == nil {
+			continue
+		}
+		for _, override := range overrides.Values {
+			name := "service_overrides." + override.Key.String() + ".field_order"
+			if err := checkFieldOrder(mappingChild(override.Value, "field_order"), name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkFieldOrder checks a single field_order sequence
+func checkFieldOrder(node ast.Node, name string) error {
+	seq, ok := node.(*ast.SequenceNode)
+	if !ok {
+		return nil
+	}
+
+	seen := make(map[string]*token.Token)
+	check := func(entry ast.Node) error {
+		scalar, ok := entry.(ast.ScalarNode)
+		if !ok {
+			return nil
+		}
+		field := fmt.Sprint(scalar.GetValue())
+		tk := entry.GetToken()
+		if first, ok := seen[field]; ok {
+			return fmt.Errorf("[%d:%d] duplicate field %q in %s, already listed at [%d:%d]",
+				tk.Position.Line, tk.Position.Column, field, name, first.Position.Line, first.Position.Column)
+		}
+		seen[field] = tk
+		return nil
+	}
+
+	for _, entry := range seq.Values {
+		values := mappingEntries(entry)
+		if values == nil {
+			if err := check(entry); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, value := range values {
+			switch key := value.Key.String(); key {
+			case "group":
+			case "fields":
+				fields, _ := value.Value.(*ast.SequenceNode)
+				if fields == nil {
+					continue
+				}
+				for _, field := range fields.Values {
+					if err := check(field); err != nil {
+						return err
+					}
+				}
+			default:
+				tk := value.Key.GetToken()
+				return fmt.Errorf("[%d:%d] unknown field %q in %s group (expected group and fields)",
+					tk.Position.Line, tk.Position.Column, key, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// mappingChild returns the value of key in a mapping node, or nil
+func mappingChild(node ast.Node, key string) ast.Node {
+	for _, value := range mappingEntries(node) {
+		if value.Key.String() == key {
+			return value.Value
+		}
+	}
+	return nil
+}
+
+// mappingEntries returns the entries of a mapping node, or nil for any
+// other node
+func mappingEntries(node ast.Node) []*ast.MappingValueNode {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		return n.Values
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}
+	}
+	return nil
+}
+
+// UnmatchedOverrides returns the service_overrides keys that match none of
+// the given services, sorted
+func (c *Config) UnmatchedOverrides(services []ServiceRef) []string {
+	unmatched := make([]string, 0)
+	for key, override := range c.ServiceOverrides {
+		matched := false
+		for _, ref := range services {
+			if matchOverride(key, override, ref) != matchNone {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, key)
+		}
+	}
+	sort.Strings(unmatched)
+	return unmatched
+}
+
+// enumKeys are the keys whose values checkEnums checks against schemaEnums
+var enumKeys = []struct {
+	section, key, enum, expected string
+}{
+	{"style", "environment", "StyleRules.environment", "list, map or consistent"},
+	{"style", "labels", "StyleRules.labels", "list, map or consistent"},
+	{"style", "quote_style", "StyleRules.quote_style", "double or single"},
+	{"format", "sequence_indent", "FormatRules.sequence_indent", "indented or compact"},
+}
+
+// checkEnums reports style and format values that decoding accepts but the
+// validator would reject, at the top level and in overrides blocks. Errors
+// carry the line and column of the value, as decoding errors do.
+func checkEnums(data []byte) error {
+	file, err := parser.ParseBytes(data, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range file.Docs {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		if err := checkEnumValues(doc.Body, ""); err != nil {
+			return err
+		}
+		overrides, _ := mappingChild(doc.Body, "overrides").(*ast.SequenceNode)
+		if overrides == nil {
+			continue
+		}
+		for i, override := range overrides.Values {
+			if err := checkEnumValues(override, fmt.Sprintf("overrides[%d].", i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkEnumValues checks the enumKeys of a single config mapping
+func checkEnumValues(node ast.Node, prefix string) error {
+	for _, enum := range enumKeys {
+		scalar, ok := mappingChild(mappingChild(node, enum.section), enum.key).(ast.ScalarNode)
+		if !ok {
+			continue
+		}
+		value := fmt.Sprint(scalar.GetValue())
+		if containsString(schemaEnums[enum.enum], value) {
+			continue
+		}
+		tk := scalar.GetToken()
+		return fmt.Errorf("[%d:%d] invalid %s%s.%s: %q (expected %s)",
+			tk.Position.Line, tk.Position.Column, prefix, enum.section, enum.key, value, enum.expected)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Comparison modes for the entries of alphabetized fields
+const (
+	// CompareCaseInsensitive ignores case; keys differing only in case are
+	// equal and keep their order
+	CompareCaseInsensitive = "case-insensitive"
+	// CompareCaseSensitive ignores case first, then puts uppercase before
+	// lowercase, so Apple sorts before apple and both before banana
+	CompareCaseSensitive = "case-sensitive"
+	// CompareNatural ignores case and compares runs of digits by their
+	// numeric value, so APP_2 sorts before APP_10
+	CompareNatural = "natural"
+	// CompareByte compares the bytes of the keys, so all uppercase keys sort
+	// before lowercase ones
+	CompareByte = "byte"
+)
+
+// ComparisonModes selects how the entries of each alphabetized field are
+// compared
+type ComparisonModes struct {
+	Environment string `yaml:"environment,omitempty"`
+	Volumes     string `yaml:"volumes,omitempty"`
+	Labels      string `yaml:"labels,omitempty"`
+}
+
+// Comparison returns the comparison mode for an alphabetized field
+func (c *Config) Comparison(field string) string {
+	modes := c.Alphabetization.Comparison
+	mode := ""
+	switch field {
+	case "environment":
+		mode = modes.Environment
+	case "volumes":
+		mode = modes.Volumes
+	case "labels":
+		mode = modes.Labels
+	}
+	if mode == "" {
+		return CompareCaseInsensitive
+	}
+	return mode
+}
+
+// CompareKeys compares two keys of alphabetized entries in the given mode,
+// returning -1, 0 or +1. The validator and fixer both sort with it, so fixed
+// files pass validation.
+func CompareKeys(mode, a, b string) int {
+	switch mode {
+	case CompareByte:
+		return strings.Compare(a, b)
+	case CompareCaseSensitive:
+		if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	case CompareNatural:
+		return compareNatural(strings.ToLower(a), strings.ToLower(b))
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareNatural compares strings with runs of digits compared by value.
+// Runs with the same value but different leading zeros are equal.
+func compareNatural(a, b string) int {
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numA, restA := digitRun(a)
+			numB, restB := digitRun(b)
+			if len(numA) != len(numB) {
+				if len(numA) < len(numB) {
+					return -1
+				}
+				return 1
+			}
+			if c := strings.Compare(numA, numB); c != 0 {
+				return c
+			}
+			a, b = restA, restB
+			continue
+		}
+		if a[0] != b[0] {
+			if a[0] < b[0] {
+				return -1
+			}
+			return 1
+		}
+		a, b = a[1:], b[1:]
+	}
+	return strings.Compare(a, b)
+}
+
+// digitRun splits a leading run of digits, without leading zeros, from s
+func digitRun(s string) (string, string) {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	return strings.TrimLeft(s[:end], "0"), s[end:]
+}
+
+// isDigit checks if a byte is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// checkComparisons checks that every comparison mode is known
+func checkComparisons(modes ComparisonModes, name string) error {
+	fields := []string{"environment", "volumes", "labels"}
+	for i, mode := range []string{modes.Environment, modes.Volumes, modes.Labels} {
+		switch mode {
+		case "", CompareCaseInsensitive, CompareCaseSensitive, CompareNatural, CompareByte:
+		default:
+			return fmt.Errorf("invalid comparison %q for %s.%s (expected case-insensitive, case-sensitive, natural or byte)", mode, name, fields[i])
+		}
+	}
+	return nil
+}
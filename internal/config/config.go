@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/goccy/go-yaml"
@@ -38,39 +39,388 @@ var DefaultFieldOrder = []string{
 	"labels",
 }
 
+// FieldGroup is a named run of consecutive fields in the field order
+type FieldGroup struct {
+	Name   string   `yaml:"group"`
+	Fields []string `yaml:"fields"`
+}
+
+// FieldOrder lists service fields in order. In YAML each entry is either a
+// field name or a named group such as {group: identity, fields: [image]};
+// groups are flattened into the list.
+type FieldOrder []string
+
+// UnmarshalYAML accepts field names and named groups
+func (o *FieldOrder) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var entries []interface{}
+	if err := unmarshal(&entries); err != nil {
+		return err
+	}
+	fields, _, err := parseFieldOrder(entries)
+	if err != nil {
+		return err
+	}
+	*o = fields
+	return nil
+}
+
+// parseFieldOrder flattens field_order entries and returns the groups they
+// form. Without any named group, no groups are returned; otherwise a bare
+// field name forms a group of its own.
+func parseFieldOrder(entries []interface{}) ([]string, []FieldGroup, error) {
+	fields := make([]string, 0, len(entries))
+	groups := make([]FieldGroup, 0)
+	named := false
+
+	for i, entry := range entries {
+		switch v := entry.(type) {
+		case string:
+			fields = append(fields, v)
+			groups = append(groups, FieldGroup{Name: v, Fields: []string{v}})
+		case map[string]interface{}:
+			name, _ := v["group"].(string)
+			list, ok := v["fields"].([]interface{})
+			if name == "" || !ok {
+				return nil, nil, fmt.Errorf("field_order entry %d: a group needs a 'group' name and a 'fields' list", i+1)
+			}
+			group := FieldGroup{Name: name}
+			for _, field := range list {
+				s, ok := field.(string)
+				if !ok {
+					return nil, nil, fmt.Errorf("field_order group '%s': fields must be strings", name)
+				}
+				group.Fields = append(group.Fields, s)
+			}
+			fields = append(fields, group.Fields...)
+			groups = append(groups, group)
+			named = true
+		default:
+			return nil, nil, fmt.Errorf("field_order entry %d: expected a field name or a group", i+1)
+		}
+	}
+
+	if !named {
+		return fields, nil, nil
+	}
+	return fields, groups, nil
+}
+
 // AlphabetizationRules defines which fields must be alphabetized
 type AlphabetizationRules struct {
 	Environment bool `yaml:"environment"`
 	Volumes     bool `yaml:"volumes"`
 	Labels      bool `yaml:"labels"`
+	// Comparison selects how the entries of each field are compared
+	Comparison ComparisonModes `yaml:"comparison"`
+}
+
+// PortRules configures host port conflict detection
+type PortRules struct {
+	// CheckConflicts reports services binding the same host IP/port/protocol
+	CheckConflicts bool `yaml:"check_conflicts"`
+	// AcrossFiles extends conflict detection to all files checked in one run
+	AcrossFiles bool `yaml:"across_files"`
+}
+
+// DuplicateRules defines which duplicate entries are reported
+type DuplicateRules struct {
+	Keys          bool `yaml:"keys"`
+	Environment   bool `yaml:"environment"`
+	Labels        bool `yaml:"labels"`
+	VolumeTargets bool `yaml:"volume_targets"`
+}
+
+// ContainerNameRules configures container_name checks
+type ContainerNameRules struct {
+	// Unique reports container names used by more than one service
+	Unique bool `yaml:"unique"`
+	// AcrossFiles extends the uniqueness check to all files checked in one run
+	AcrossFiles bool `yaml:"across_files"`
+	// Pattern is a regular expression container names must match in full.
+	// The placeholders {project} and {service} are replaced by the literal
+	// project and service names, e.g. "{project}-{service}".
+	Pattern string `yaml:"pattern"`
+}
+
+// ImageRules configures the image reference policy
+type ImageRules struct {
+	RequireTag     bool `yaml:"require_tag"`
+	DisallowLatest bool `yaml:"disallow_latest"`
+	RequireDigest  bool `yaml:"require_digest"`
+	// AllowedRegistries lists registry hosts images may be pulled from,
+	// with "docker.io" standing for Docker Hub
+	AllowedRegistries []string `yaml:"allowed_registries"`
+	// AllowedRepositories lists glob patterns such as "ghcr.io/myorg/*"
+	// matched against the repository as written or fully qualified
+	AllowedRepositories []string `yaml:"allowed_repositories"`
+}
+
+// SecurityCheck configures a single check of the security rule pack
+type SecurityCheck struct {
+	Enabled bool `yaml:"enabled"`
+	// Allow lists service name glob patterns exempt from the check
+	Allow []string `yaml:"allow"`
+}
+
+// IsAllowed checks if a service is exempt from the check
+func (c SecurityCheck) IsAllowed(serviceName string) bool {
+	for _, pattern := range c.Allow {
+		if matched, _ := filepath.Match(pattern, serviceName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SecurityRules configures the opt-in security hardening rule pack
+type SecurityRules struct {
+	Enabled         bool          `yaml:"enabled"`
+	Privileged      SecurityCheck `yaml:"privileged"`
+	CapAdd          SecurityCheck `yaml:"cap_add"`
+	NetworkModeHost SecurityCheck `yaml:"network_mode_host"`
+	PIDHost         SecurityCheck `yaml:"pid_host"`
+	DockerSocket    SecurityCheck `yaml:"docker_socket"`
+	SensitiveMounts SecurityCheck `yaml:"sensitive_mounts"`
+	SecurityOpt     SecurityCheck `yaml:"security_opt"`
+	MissingUser     SecurityCheck `yaml:"missing_user"`
+	// DangerousCapabilities lists capabilities reported by the cap_add check
+	DangerousCapabilities []string `yaml:"dangerous_capabilities"`
+	// SensitivePaths lists host paths that must not be bind mounted writable
+	SensitivePaths []string `yaml:"sensitive_paths"`
+}
+
+// SecretRules configures plaintext secret detection in environment and labels
+type SecretRules struct {
+	Enabled bool `yaml:"enabled"`
+	// SensitiveKeys lists key glob patterns that must not carry literal values
+	SensitiveKeys []string `yaml:"sensitive_keys"`
+	// EntropyThreshold is the Shannon entropy in bits per character above
+	// which a value of at least MinLength characters is reported
+	EntropyThreshold float64 `yaml:"entropy_threshold"`
+	MinLength        int     `yaml:"min_length"`
+	// Allow lists key glob patterns that are never reported
+	Allow []string `yaml:"allow"`
+}
+
+// TypoRules configures "did you mean" suggestions for unknown service fields
+type TypoRules struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxDistance is the largest edit distance at which a suggestion is made
+	MaxDistance int `yaml:"max_distance"`
+}
+
+// DeprecationRules configures detection of obsolete Compose syntax
+type DeprecationRules struct {
+	Enabled bool `yaml:"enabled"`
+	// Version reports the obsolete top-level version key
+	Version       bool `yaml:"version"`
+	Links         bool `yaml:"links"`
+	ExternalLinks bool `yaml:"external_links"`
+	VolumesFrom   bool `yaml:"volumes_from"`
+	// ContainerNameReplicas reports container_name on services scaled with
+	// deploy.replicas, which Compose cannot start more than once
+	ContainerNameReplicas bool `yaml:"container_name_replicas"`
+}
+
+// StyleRules configures how values are written
+type StyleRules struct {
+	// Environment and Labels select "list" (KEY=value entries), "map"
+	// (KEY: value pairs) or "consistent" (whichever form the first service
+	// in the file uses). Empty allows both forms.
+	Environment string `yaml:"environment"`
+	Labels      string `yaml:"labels"`
+	// QuotePorts requires short-syntax port mappings to be quoted, since
+	// YAML 1.1 tooling reads unquoted values like 80:80 as numbers
+	QuotePorts bool `yaml:"quote_ports"`
+	// QuoteLabelValues requires label values to be quoted strings
+	QuoteLabelValues bool `yaml:"quote_label_values"`
+	// QuoteVersions requires version-like values such as 3.8 to be quoted
+	QuoteVersions bool `yaml:"quote_versions"`
+	// QuoteStyle selects "double" or "single" quotes; empty allows both
+	QuoteStyle string `yaml:"quote_style"`
 }
 
-// ServiceOverride allows custom field order for specific services
+// FormatRules configures layout checks
+type FormatRules struct {
+	Enabled bool `yaml:"enabled"`
+	// Indent is the number of spaces per nesting level
+	Indent int `yaml:"indent"`
+	// SequenceIndent selects "indented" (entries indented under their key),
+	// "compact" (dashes aligned with the key) or empty to allow both
+	SequenceIndent     string `yaml:"sequence_indent"`
+	TrailingWhitespace bool   `yaml:"trailing_whitespace"`
+	// BlankLinesBetweenServices is the number of blank lines separating
+	// services; a negative value disables the check
+	BlankLinesBetweenServices int  `yaml:"blank_lines_between_services"`
+	FinalNewline              bool `yaml:"final_newline"`
+}
+
+// FieldPolicy requires or forbids fields on services matching a service
+// name glob or an image pattern
+type FieldPolicy struct {
+	Services        []string `yaml:"services"`
+	Images          []string `yaml:"images"`
+	RequiredFields  []string `yaml:"required_fields"`
+	ForbiddenFields []string `yaml:"forbidden_fields"`
+}
+
+// Matches checks if the policy applies to a service
+func (p FieldPolicy) Matches(serviceName, image string) bool {
+	for _, pattern := range p.Services {
+		if matched, _ := filepath.Match(pattern, serviceName); matched {
+			return true
+		}
+	}
+	if image == "" {
+		return false
+	}
+	for _, pattern := range p.Images {
+		if matched, _ := filepath.Match(pattern, image); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceOverride changes settings for the services it matches. Its key in
+// service_overrides is a service name, a glob such as "db-*" or a regular
+// expression written as "/^worker-[0-9]+$/". An override that sets Images or
+// Labels matches by those instead, and its key only names it.
 type ServiceOverride struct {
-	FieldOrder []string `yaml:"field_order"`
+	// Images lists image patterns such as "postgres:*"
+	Images []string `yaml:"images,omitempty"`
+	// Labels lists labels a service must carry; values are glob patterns
+	Labels          map[string]string       `yaml:"labels,omitempty"`
+	FieldOrder      FieldOrder              `yaml:"field_order,omitempty"`
+	RequiredFields  []string                `yaml:"required_fields,omitempty"`
+	ForbiddenFields []string                `yaml:"forbidden_fields,omitempty"`
+	Alphabetization AlphabetizationOverride `yaml:"alphabetization,omitempty"`
+	Strict          *bool                   `yaml:"strict,omitempty"`
+	Severities      map[string]string       `yaml:"severities,omitempty"`
+	// FieldGroups are the named groups of FieldOrder, if any
+	FieldGroups []FieldGroup `yaml:"-"`
+}
+
+// AlphabetizationOverride changes single alphabetization rules; unset rules
+// keep their value
+type AlphabetizationOverride struct {
+	Environment *bool `yaml:"environment,omitempty"`
+	Volumes     *bool `yaml:"volumes,omitempty"`
+	Labels      *bool `yaml:"labels,omitempty"`
+	// Comparison changes the comparison modes it sets
+	Comparison ComparisonModes `yaml:"comparison,omitempty"`
 }
 
 // Config represents the validator configuration
 type Config struct {
-	FieldOrder       []string                   `yaml:"field_order"`
+	// Sources lists what the configuration was built from, in the order
+	// applied: config files, presets, overrides blocks and service_overrides
+	Sources          []string                   `yaml:"-"`
+	Root             bool                       `yaml:"root,omitempty"`
+	Extends          string                     `yaml:"extends,omitempty"`
+	FieldOrder       FieldOrder                 `yaml:"field_order"`
+	FieldGroups      []FieldGroup               `yaml:"-"`
+	GroupSeparators  bool                       `yaml:"group_separators"`
+	UnlistedOrder    string                     `yaml:"unlisted_order"`
 	Alphabetization  AlphabetizationRules       `yaml:"alphabetization"`
 	Strict           bool                       `yaml:"strict"`
 	Exclude          []string                   `yaml:"exclude"`
 	ServiceOverrides map[string]ServiceOverride `yaml:"service_overrides"`
+	// Severities maps violation types such as "order" to error, warning or off
+	Severities map[string]string `yaml:"severities"`
+	// Overrides adjust settings for files matching path globs
+	Overrides        []PathOverride     `yaml:"overrides"`
+	Ports            PortRules          `yaml:"ports"`
+	Duplicates       DuplicateRules     `yaml:"duplicates"`
+	ContainerNames   ContainerNameRules `yaml:"container_names"`
+	Images           ImageRules         `yaml:"images"`
+	Security         SecurityRules      `yaml:"security"`
+	RequiredFields   []string           `yaml:"required_fields"`
+	ForbiddenFields  []string           `yaml:"forbidden_fields"`
+	FieldPolicies    []FieldPolicy      `yaml:"field_policies"`
+	Secrets          SecretRules        `yaml:"secrets"`
+	SchemaValidation bool               `yaml:"schema_validation"`
+	Typos            TypoRules          `yaml:"typos"`
+	Deprecations     DeprecationRules   `yaml:"deprecations"`
+	Style            StyleRules         `yaml:"style"`
+	Format           FormatRules        `yaml:"format"`
 }
 
 // NewDefaultConfig creates a default configuration
 func NewDefaultConfig() *Config {
 	return &Config{
-		FieldOrder: DefaultFieldOrder,
+		FieldOrder:    DefaultFieldOrder,
+		UnlistedOrder: "original",
 		Alphabetization: AlphabetizationRules{
 			Environment: true,
 			Volumes:     true,
 			Labels:      true,
+			Comparison: ComparisonModes{
+				Environment: CompareCaseInsensitive,
+				Volumes:     CompareCaseInsensitive,
+				Labels:      CompareCaseInsensitive,
+			},
 		},
 		Strict:           false,
 		Exclude:          []string{},
 		ServiceOverrides: make(map[string]ServiceOverride),
+		Ports: PortRules{
+			CheckConflicts: true,
+			AcrossFiles:    false,
+		},
+		Duplicates: DuplicateRules{
+			Keys:          true,
+			Environment:   true,
+			Labels:        true,
+			VolumeTargets: true,
+		},
+		ContainerNames: ContainerNameRules{
+			Unique:      true,
+			AcrossFiles: false,
+		},
+		Security: SecurityRules{
+			Enabled:               false,
+			Privileged:            SecurityCheck{Enabled: true},
+			CapAdd:                SecurityCheck{Enabled: true},
+			NetworkModeHost:       SecurityCheck{Enabled: true},
+			PIDHost:               SecurityCheck{Enabled: true},
+			DockerSocket:          SecurityCheck{Enabled: true},
+			SensitiveMounts:       SecurityCheck{Enabled: true},
+			SecurityOpt:           SecurityCheck{Enabled: true},
+			MissingUser:           SecurityCheck{Enabled: true},
+			DangerousCapabilities: []string{"ALL", "NET_ADMIN", "SYS_ADMIN"},
+			SensitivePaths:        []string{"/", "/etc", "/proc"},
+		},
+		Secrets: SecretRules{
+			Enabled: false,
+			SensitiveKeys: []string{
+				"*PASSWORD", "*PASSWD", "*SECRET", "*TOKEN", "*API_KEY", "*PRIVATE_KEY",
+			},
+			EntropyThreshold: 4.5,
+			MinLength:        20,
+			Allow:            []string{},
+		},
+		SchemaValidation: true,
+		Typos: TypoRules{
+			Enabled:     true,
+			MaxDistance: 2,
+		},
+		Deprecations: DeprecationRules{
+			Enabled:               false,
+			Version:               true,
+			Links:                 true,
+			ExternalLinks:         true,
+			VolumesFrom:           true,
+			ContainerNameReplicas: true,
+		},
+		Format: FormatRules{
+			Enabled:                   false,
+			Indent:                    2,
+			SequenceIndent:            "indented",
+			TrailingWhitespace:        true,
+			BlankLinesBetweenServices: 1,
+			FinalNewline:              true,
+		},
 	}
 }
 
@@ -82,60 +432,343 @@ var configFileNames = []string{
 	"compose-validator.yml",
 }
 
-// Load attempts to load configuration from standard locations
+// Load loads the configuration for the current directory
 func Load() (*Config, error) {
-	// Try to find config in current directory and parent directories
 	dir, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get working directory: %w", err)
 	}
+	return LoadForDir(dir)
+}
+
+// LoadForFile loads the configuration that applies to a compose file,
+// starting from the file's own directory
+func LoadForFile(path string) (*Config, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+	return LoadForDir(filepath.Dir(abs))
+}
+
+// LoadForDir merges the config files found in dir and its parent
+// directories, up to the first one marked root: true. Configs closer to dir
+// are applied last. Without any config file, the defaults are returned.
+func LoadForDir(dir string) (*Config, error) {
+	paths, err := findConfigFiles(dir)
+	if err != nil {
+		return nil, err
+	}
 
+	cfg := NewDefaultConfig()
+	for i := len(paths) - 1; i >= 0; i-- {
+		cfg, err = loadFile(paths[i], cfg, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// findConfigFiles returns the config file of dir and of each parent
+// directory, nearest first, stopping at a config marked root: true
+func findConfigFiles(dir string) ([]string, error) {
+	paths := make([]string, 0)
 	for {
 		for _, name := range configFileNames {
 			path := filepath.Join(dir, name)
-			if _, err := os.Stat(path); err == nil {
-				return LoadFromFile(path)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			paths = append(paths, path)
+			root, err := isRootConfig(path)
+			if err != nil {
+				return nil, err
+			}
+			if root {
+				return paths, nil
 			}
+			break
 		}
 
 		// Move up to parent directory
 		parent := filepath.Dir(dir)
 		if parent == dir {
-			break
+			return paths, nil
 		}
 		dir = parent
 	}
+}
 
-	// No config found, return default
-	return NewDefaultConfig(), nil
+// isRootConfig checks if a config file stops the search for parent configs
+func isRootConfig(path string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	var head struct {
+		Root bool `yaml:"root"`
+	}
+	if err := yaml.Unmarshal(data, &head); err != nil {
+		return false, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return head.Root, nil
 }
 
-// LoadFromFile loads configuration from a specific file
+// LoadFromFile loads configuration from a specific file, on top of the
+// config it extends
 func LoadFromFile(path string) (*Config, error) {
+	return loadFile(path, nil, nil)
+}
+
+// loadFile loads a config file on top of base, or the defaults if base is
+// nil. chain lists the configs being loaded, so an extends cycle is reported
+// instead of followed.
+func loadFile(path string, base *Config, chain []string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
 	}
 
-	cfg := NewDefaultConfig()
-	if err := yaml.Unmarshal(data, cfg); err != nil {
+	key := path
+	if abs, err := filepath.Abs(path); err == nil {
+		key = abs
+	}
+	cfg, err := loadConfig(data, filepath.Dir(path), base, append(chain, key))
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
 	}
+	cfg.addSource(key)
+
+	return cfg, nil
+}
+
+// addSource records a config file or preset as applied, along with the
+// overrides blocks it defines
+func (c *Config) addSource(source string) {
+	c.Sources = append(append([]string{}, c.Sources...), source)
+	for i := range c.Overrides {
+		if c.Overrides[i].source == "" {
+			c.Overrides[i].source = source
+		}
+	}
+}
+
+// loadConfig decodes config data on top of base, or the defaults if base is
+// nil. A config it extends is applied first. Relative extends paths are
+// resolved against dir.
+func loadConfig(data []byte, dir string, base *Config, chain []string) (*Config, error) {
+	var head struct {
+		Extends string `yaml:"extends"`
+	}
+	if err := yaml.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+
+	cfg := base
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+	if head.Extends != "" {
+		extended, err := loadBase(head.Extends, dir, cfg, chain)
+		if err != nil {
+			return nil, err
+		}
+		cfg = extended
+	}
+
+	if err := checkFieldOrders(data); err != nil {
+		return nil, err
+	}
+	if err := checkEnums(data); err != nil {
+		return nil, err
+	}
+
+	// Unknown keys are rejected, so a misspelled setting is not ignored
+	inherited := *cfg
+	if err := yaml.UnmarshalWithOptions(data, cfg, yaml.DisallowUnknownField()); err != nil {
+		return nil, err
+	}
+	if err := mergeExtended(data, dir, cfg, inherited); err != nil {
+		return nil, err
+	}
 
 	// Validate that field order is not empty
 	if len(cfg.FieldOrder) == 0 {
 		cfg.FieldOrder = DefaultFieldOrder
 	}
 
+	if err := loadFieldGroups(data, cfg); err != nil {
+		return nil, err
+	}
+
+	switch cfg.UnlistedOrder {
+	case "", "original", "alphabetical":
+	default:
+		return nil, fmt.Errorf("invalid unlisted_order: %q (expected original or alphabetical)", cfg.UnlistedOrder)
+	}
+
+	if err := checkOverrides(cfg); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
 // GetFieldOrder returns the field order for a specific service
 func (c *Config) GetFieldOrder(serviceName string) []string {
-	if override, ok := c.ServiceOverrides[serviceName]; ok && len(override.FieldOrder) > 0 {
-		return override.FieldOrder
+	return c.ForService(ServiceRef{Name: serviceName}).FieldOrder
+}
+
+// UnlistedFields is the field_order entry that marks where fields not
+// matched by any other entry belong
+const UnlistedFields = "*"
+
+// FieldPosition returns the index of the field_order entry that places field:
+// the field itself, else the first pattern it matches such as "x-*", else the
+// "*" placeholder. It returns -1 if no entry places the field.
+func FieldPosition(fieldOrder []string, field string) int {
+	for i, entry := range fieldOrder {
+		if entry == field {
+			return i
+		}
+	}
+	for i, entry := range fieldOrder {
+		if entry == UnlistedFields || !strings.ContainsAny(entry, "*?[") {
+			continue
+		}
+		if matched, _ := filepath.Match(entry, field); matched {
+			return i
+		}
+	}
+	for i, entry := range fieldOrder {
+		if entry == UnlistedFields {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsListedField checks if field is named in field_order or matches one of
+// its patterns. The "*" placeholder only marks a position.
+func IsListedField(fieldOrder []string, field string) bool {
+	pos := FieldPosition(fieldOrder, field)
+	return pos >= 0 && fieldOrder[pos] != UnlistedFields
+}
+
+// OrderFields returns the fields placed by fieldOrder in their expected
+// order, leaving out fields without a position. Fields sharing a pattern or
+// the "*" placeholder keep their original order, or are sorted by name when
+// unlisted_order is alphabetical.
+func (c *Config) OrderFields(fieldOrder, fields []string) []string {
+	ordered := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if FieldPosition(fieldOrder, field) >= 0 {
+			ordered = append(ordered, field)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		pi, pj := FieldPosition(fieldOrder, ordered[i]), FieldPosition(fieldOrder, ordered[j])
+		if pi != pj {
+			return pi < pj
+		}
+		return c.UnlistedOrder == "alphabetical" && ordered[i] < ordered[j]
+	})
+	return ordered
+}
+
+// GetFieldGroups returns the named field groups for a specific service, or
+// nil if its field order is a flat list
+func (c *Config) GetFieldGroups(serviceName string) []FieldGroup {
+	return c.ForService(ServiceRef{Name: serviceName}).FieldGroups
+}
+
+// GroupAt returns the index of the group holding the flattened field_order
+// entry at position, or -1 if there is none
+func GroupAt(groups []FieldGroup, position int) int {
+	if position < 0 {
+		return -1
+	}
+	for i, group := range groups {
+		if position < len(group.Fields) {
+			return i
+		}
+		position -= len(group.Fields)
+	}
+	return -1
+}
+
+// loadFieldGroups records the groups of grouped field_order lists, which
+// FieldOrder flattens when decoding
+func loadFieldGroups(data []byte, cfg *Config) error {
+	var raw struct {
+		FieldOrder       []interface{} `yaml:"field_order"`
+		ServiceOverrides map[string]struct {
+			FieldOrder []interface{} `yaml:"field_order"`
+		} `yaml:"service_overrides"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if raw.FieldOrder != nil {
+		_, groups, err := parseFieldOrder(raw.FieldOrder)
+		if err != nil {
+			return err
+		}
+		cfg.FieldGroups = groups
+	}
+
+	for name, override := range raw.ServiceOverrides {
+		if override.FieldOrder == nil {
+			continue
+		}
+		_, groups, err := parseFieldOrder(override.FieldOrder)
+		if err != nil {
+			return err
+		}
+		existing := cfg.ServiceOverrides[name]
+		existing.FieldGroups = groups
+		cfg.ServiceOverrides[name] = existing
+	}
+
+	return nil
+}
+
+// GetFieldPolicy returns the fields a service must and must not define,
+// combining the global lists, the service override and matching policies.
+// Field names may be dotted paths such as "deploy.resources.limits".
+func (c *Config) GetFieldPolicy(serviceName, image string) (required, forbidden []string) {
+	service := c.ForService(ServiceRef{Name: serviceName, Image: image})
+	required = appendUnique(required, service.RequiredFields...)
+	forbidden = appendUnique(forbidden, service.ForbiddenFields...)
+
+	for _, policy := range c.FieldPolicies {
+		if policy.Matches(serviceName, image) {
+			required = appendUnique(required, policy.RequiredFields...)
+			forbidden = appendUnique(forbidden, policy.ForbiddenFields...)
+		}
+	}
+
+	return required, forbidden
+}
+
+// appendUnique appends values not already present in list
+func appendUnique(list []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+		for _, existing := range list {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, value)
+		}
 	}
-	return c.FieldOrder
+	return list
 }
 
 // ShouldAlphabetize checks if a field should be alphabetized
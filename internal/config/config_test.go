@@ -1,9 +1,16 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/santhosh-tekuri/jsonschema/v5"
 )
 
 func TestNewDefaultConfig(t *testing.T) {
@@ -197,6 +204,48 @@ func TestGetFieldOrder(t *testing.T) {
 	}
 }
 
+func TestGetFieldPolicy(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.RequiredFields = []string{"restart"}
+	cfg.ForbiddenFields = []string{"privileged"}
+	cfg.ServiceOverrides = map[string]ServiceOverride{
+		"database": {
+			RequiredFields: []string{"healthcheck", "restart"},
+		},
+	}
+	cfg.FieldPolicies = []FieldPolicy{
+		{
+			Services:       []string{"worker-*"},
+			RequiredFields: []string{"deploy.resources.limits"},
+		},
+		{
+			Images:          []string{"postgres:*"},
+			ForbiddenFields: []string{"ports"},
+		},
+	}
+
+	tests := []struct {
+		service   string
+		image     string
+		required  []string
+		forbidden []string
+	}{
+		{"web", "nginx:1.25", []string{"restart"}, []string{"privileged"}},
+		{"database", "postgres:16", []string{"restart", "healthcheck"}, []string{"privileged", "ports"}},
+		{"worker-email", "", []string{"restart", "deploy.resources.limits"}, []string{"privileged"}},
+	}
+
+	for _, tt := range tests {
+		required, forbidden := cfg.GetFieldPolicy(tt.service, tt.image)
+		if strings.Join(required, ",") != strings.Join(tt.required, ",") {
+			t.Errorf("%s: expected required %v, got %v", tt.service, tt.required, required)
+		}
+		if strings.Join(forbidden, ",") != strings.Join(tt.forbidden, ",") {
+			t.Errorf("%s: expected forbidden %v, got %v", tt.service, tt.forbidden, forbidden)
+		}
+	}
+}
+
 func TestShouldAlphabetize(t *testing.T) {
 	cfg := NewDefaultConfig()
 
@@ -323,3 +372,1146 @@ strict: true
 		t.Error("Expected strict=true from parent config")
 	}
 }
+
+func TestLoadFromFile_FieldGroups(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "groups.yaml")
+	content := `
+field_order:
+  - group: identity
+    fields: [container_name, image]
+  - build
+  - group: runtime
+    fields: [command, environment]
+group_separators: true
+service_overrides:
+  db:
+    field_order:
+      - image
+      - environment
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"container_name", "image", "build", "command", "environment"}
+	if strings.Join(cfg.FieldOrder, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected field order %v, got %v", expected, cfg.FieldOrder)
+	}
+	if !cfg.GroupSeparators {
+		t.Error("Expected group_separators to be enabled")
+	}
+
+	groups := cfg.GetFieldGroups("web")
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %+v", groups)
+	}
+	if groups[0].Name != "identity" || groups[1].Name != "build" || len(groups[2].Fields) != 2 {
+		t.Errorf("Unexpected groups: %+v", groups)
+	}
+
+	// A flat override has no groups
+	if groups := cfg.GetFieldGroups("db"); groups != nil {
+		t.Errorf("Expected no groups for flat override, got %+v", groups)
+	}
+	if order := cfg.GetFieldOrder("db"); len(order) != 2 {
+		t.Errorf("Expected 2 fields for override, got %v", order)
+	}
+
+	invalidPath := filepath.Join(tmpDir, "invalid.yaml")
+	if err := os.WriteFile(invalidPath, []byte("field_order:\n  - group: identity\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	if _, err := LoadFromFile(invalidPath); err == nil {
+		t.Error("Expected error for group without fields")
+	}
+}
+
+func TestFieldPosition(t *testing.T) {
+	fieldOrder := []string{"image", "x-*", "*", "environment", "x-custom"}
+
+	tests := []struct {
+		field    string
+		expected int
+	}{
+		{"image", 0},
+		{"x-custom", 4}, // exact entries win over patterns
+		{"x-other", 1},
+		{"depends_on", 2},
+		{"environment", 3},
+	}
+	for _, test := range tests {
+		if pos := FieldPosition(fieldOrder, test.field); pos != test.expected {
+			t.Errorf("FieldPosition(%q) = %d, expected %d", test.field, pos, test.expected)
+		}
+	}
+
+	if pos := FieldPosition([]string{"image"}, "depends_on"); pos != -1 {
+		t.Errorf("Expected -1 without placeholder, got %d", pos)
+	}
+	if IsListedField(fieldOrder, "depends_on") {
+		t.Error("Fields placed by the placeholder should not be listed")
+	}
+	if !IsListedField(fieldOrder, "x-other") {
+		t.Error("Fields matching a pattern should be listed")
+	}
+}
+
+func TestOrderFields(t *testing.T) {
+	cfg := NewDefaultConfig()
+	fieldOrder := []string{"image", "*", "environment"}
+	fields := []string{"environment", "restart", "image", "command"}
+
+	ordered := cfg.OrderFields(fieldOrder, fields)
+	if strings.Join(ordered, ",") != "image,restart,command,environment" {
+		t.Errorf("Unexpected original order: %v", ordered)
+	}
+
+	cfg.UnlistedOrder = "alphabetical"
+	ordered = cfg.OrderFields(fieldOrder, fields)
+	if strings.Join(ordered, ",") != "image,command,restart,environment" {
+		t.Errorf("Unexpected alphabetical order: %v", ordered)
+	}
+
+	// Fields without a position are left out
+	ordered = cfg.OrderFields([]string{"image", "environment"}, fields)
+	if strings.Join(ordered, ",") != "image,environment" {
+		t.Errorf("Expected unplaced fields to be left out, got %v", ordered)
+	}
+}
+
+func TestLoadFromFile_Extends(t *testing.T) {
+	tmpDir := t.TempDir()
+	shared := filepath.Join(tmpDir, "shared")
+	if err := os.Mkdir(shared, 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+
+	base := `
+extends: security
+field_order:
+  - image
+  - environment
+alphabetization:
+  volumes: false
+exclude:
+  - "**/test/**"
+service_overrides:
+  db:
+    field_order: [image, volumes]
+    required_fields: [healthcheck]
+  cache:
+    forbidden_fields: [ports]
+`
+	child := `
+extends: shared/base.yaml
+alphabetization:
+  labels: false
+exclude:
+  - "**/vendor/**"
+service_overrides:
+  db:
+    required_fields: [restart]
+`
+	if err := os.WriteFile(filepath.Join(shared, "base.yaml"), []byte(base), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	childPath := filepath.Join(tmpDir, "child.yaml")
+	if err := os.WriteFile(childPath, []byte(child), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+
+	cfg, err := LoadFromFile(childPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The preset extended by the base applies
+	if !cfg.Security.Enabled || !cfg.Images.RequireTag {
+		t.Error("Expected settings from the security preset")
+	}
+	// field_order is inherited as a whole
+	if strings.Join(cfg.FieldOrder, ",") != "image,environment" {
+		t.Errorf("Expected inherited field order, got %v", cfg.FieldOrder)
+	}
+	// Alphabetization rules merge key by key
+	if cfg.Alphabetization.Volumes || cfg.Alphabetization.Labels || !cfg.Alphabetization.Environment {
+		t.Errorf("Unexpected alphabetization rules: %+v", cfg.Alphabetization)
+	}
+	// Exclude patterns are added
+	if strings.Join(cfg.Exclude, ",") != "**/test/**,**/vendor/**" {
+		t.Errorf("Expected merged excludes, got %v", cfg.Exclude)
+	}
+	// Overrides merge per service
+	db := cfg.ServiceOverrides["db"]
+	if strings.Join(db.FieldOrder, ",") != "image,volumes" || strings.Join(db.RequiredFields, ",") != "restart" {
+		t.Errorf("Unexpected db override: %+v", db)
+	}
+	if _, ok := cfg.ServiceOverrides["cache"]; !ok {
+		t.Error("Expected cache override from the base config")
+	}
+}
+
+func TestLoadFromFile_ExtendsPresets(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		check   func(*Config) bool
+	}{
+		{"default", "extends: default\n", func(c *Config) bool { return !c.Strict && len(c.FieldOrder) == len(DefaultFieldOrder) }},
+		{"strict", "extends: strict\n", func(c *Config) bool { return c.Strict && c.Format.Enabled && c.Deprecations.Enabled }},
+		{"override", "extends: strict\nstrict: false\n", func(c *Config) bool { return !c.Strict && c.Format.Enabled }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configPath := filepath.Join(tmpDir, test.name+".yaml")
+			if err := os.WriteFile(configPath, []byte(test.content), 0644); err != nil {
+				t.Fatalf("Failed to create test config: %v", err)
+			}
+			cfg, err := LoadFromFile(configPath)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !test.check(cfg) {
+				t.Errorf("Unexpected config: %+v", cfg)
+			}
+		})
+	}
+}
+
+func TestLoadFromFile_ExtendsErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"a.yaml":       "extends: b.yaml\n",
+		"b.yaml":       "extends: a.yaml\n",
+		"missing.yaml": "extends: strcit\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config: %v", err)
+		}
+	}
+
+	_, err := LoadFromFile(filepath.Join(tmpDir, "a.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "extends cycle") {
+		t.Errorf("Expected extends cycle error, got %v", err)
+	}
+
+	if _, err := LoadFromFile(filepath.Join(tmpDir, "missing.yaml")); err == nil {
+		t.Error("Expected error for unknown preset")
+	}
+}
+
+func TestLoadForDir_Hierarchy(t *testing.T) {
+	tmpDir := t.TempDir()
+	repoDir := filepath.Join(tmpDir, "repo")
+	teamDir := filepath.Join(repoDir, "team")
+	appDir := filepath.Join(teamDir, "app")
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		t.Fatalf("Failed to create dirs: %v", err)
+	}
+
+	configs := map[string]string{
+		// Above the root marker, never read
+		tmpDir: "strict: true\n",
+		repoDir: `
+root: true
+field_order: [container_name, image]
+exclude: ["**/test/**"]
+alphabetization:
+  volumes: false
+`,
+		teamDir: `
+exclude: ["**/vendor/**"]
+alphabetization:
+  labels: false
+`,
+	}
+	for dir, content := range configs {
+		if err := os.WriteFile(filepath.Join(dir, ".compose-validator.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create config: %v", err)
+		}
+	}
+
+	cfg, err := LoadForDir(appDir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cfg.Strict {
+		t.Error("Configs above the root marker should not apply")
+	}
+	if len(cfg.FieldOrder) != 2 {
+		t.Errorf("Expected field order from the repo config, got %v", cfg.FieldOrder)
+	}
+	if cfg.Alphabetization.Volumes || cfg.Alphabetization.Labels {
+		t.Errorf("Expected alphabetization rules from both configs, got %+v", cfg.Alphabetization)
+	}
+	if strings.Join(cfg.Exclude, ",") != "**/test/**,**/vendor/**" {
+		t.Errorf("Expected merged excludes, got %v", cfg.Exclude)
+	}
+
+	// A sibling directory only gets the repo config
+	cfg, err = LoadForFile(filepath.Join(repoDir, "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !cfg.Alphabetization.Labels || cfg.Alphabetization.Volumes {
+		t.Errorf("Expected only the repo config, got %+v", cfg.Alphabetization)
+	}
+}
+
+func TestLoadFromFile_StrictErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		contains string
+	}{
+		{
+			name:     "unknown top-level key",
+			content:  "strict: true\nalphabetisation:\n  environment: true\n",
+			contains: `[2:1] unknown field "alphabetisation"`,
+		},
+		{
+			name:     "unknown nested key",
+			content:  "service_overrides:\n  db:\n    field-order: [image]\n",
+			contains: `[3:5] unknown field "field-order"`,
+		},
+		{
+			name:     "duplicate field",
+			content:  "field_order:\n  - image\n  - ports\n  - image\n",
+			contains: `[4:5] duplicate field "image" in field_order, already listed at [2:5]`,
+		},
+		{
+			name:     "duplicate field across groups",
+			content:  "field_order:\n  - group: identity\n    fields: [image]\n  - group: runtime\n    fields: [ports, image]\n",
+			contains: `duplicate field "image"`,
+		},
+		{
+			name:     "duplicate field in override",
+			content:  "service_overrides:\n  db:\n    field_order: [image, image]\n",
+			contains: "in service_overrides.db.field_order",
+		},
+		{
+			name:     "unknown group key",
+			content:  "field_order:\n  - group: identity\n    feilds: [image]\n",
+			contains: `[3:5] unknown field "feilds" in field_order group`,
+		},
+		{
+			name:     "unknown violation type",
+			content:  "severities:\n  secrets: off\n",
+			contains: `unknown violation type "secrets" in severities (expected one of order, alphabetization,`,
+		},
+		{
+			name:     "unknown violation type in override",
+			content:  "service_overrides:\n  db:\n    severities:\n      alphabetisation: warning\n",
+			contains: `unknown violation type "alphabetisation" in service_overrides.db.severities`,
+		},
+		{
+			name:     "unknown style",
+			content:  "style:\n  environment: lists\n",
+			contains: `[2:16] invalid style.environment: "lists" (expected list, map or consistent)`,
+		},
+		{
+			name:     "unknown quote style",
+			content:  "style:\n  labels: map\n  quote_style: backtick\n",
+			contains: `[3:16] invalid style.quote_style: "backtick"`,
+		},
+		{
+			name:     "unknown sequence indent",
+			content:  "format:\n  sequence_indent: flat\n",
+			contains: `[2:20] invalid format.sequence_indent: "flat" (expected indented or compact)`,
+		},
+		{
+			name:     "unknown style in overrides block",
+			content:  "overrides:\n  - files: [\"*.yml\"]\n    style:\n      labels: dict\n",
+			contains: `[4:15] invalid overrides[0].style.labels: "dict"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configPath := filepath.Join(tmpDir, "config.yaml")
+			if err := os.WriteFile(configPath, []byte(test.content), 0644); err != nil {
+				t.Fatalf("Failed to create test config: %v", err)
+			}
+			_, err := LoadFromFile(configPath)
+			if err == nil {
+				t.Fatal("Expected error but got none")
+			}
+			if !strings.Contains(err.Error(), test.contains) {
+				t.Errorf("Expected error containing %q, got %v", test.contains, err)
+			}
+		})
+	}
+}
+
+func TestUnmatchedOverrides(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.ServiceOverrides = map[string]ServiceOverride{
+		"web": {},
+		"wbe": {},
+		"db":  {},
+	}
+
+	unmatched := cfg.UnmatchedOverrides([]ServiceRef{{Name: "web"}, {Name: "cache"}})
+	if strings.Join(unmatched, ",") != "db,wbe" {
+		t.Errorf("Expected db and wbe to be unmatched, got %v", unmatched)
+	}
+}
+
+func TestForService(t *testing.T) {
+	enabled, disabled := true, false
+	cfg := NewDefaultConfig()
+	cfg.Severities = map[string]string{"order": "warning"}
+	cfg.ServiceOverrides = map[string]ServiceOverride{
+		"db-*": {
+			FieldOrder:     FieldOrder{"image", "environment"},
+			RequiredFields: []string{"healthcheck"},
+			Strict:         &enabled,
+		},
+		"/^worker-[0-9]+$/": {
+			Alphabetization: AlphabetizationOverride{Environment: &disabled},
+		},
+		"postgres": {
+			Images:     []string{"postgres:*"},
+			FieldOrder: FieldOrder{"image"},
+			Severities: map[string]string{"alphabetization": "off"},
+		},
+		"backend": {
+			Labels: map[string]string{"com.example.tier": "back*"},
+			Strict: &disabled,
+		},
+		"db-main": {
+			FieldOrder: FieldOrder{"container_name", "image"},
+		},
+	}
+
+	// Glob, then exact name: the exact override wins field_order
+	main := cfg.ForService(ServiceRef{Name: "db-main", Image: "postgres:16"})
+	if strings.Join(main.FieldOrder, ",") != "container_name,image" {
+		t.Errorf("Expected exact override field order, got %v", main.FieldOrder)
+	}
+	if !main.Strict || len(main.RequiredFields) != 1 {
+		t.Errorf("Expected strict and required fields from the glob override, got %v %v", main.Strict, main.RequiredFields)
+	}
+	if main.Severity("alphabetization") != "off" || main.Severity("order") != "warning" {
+		t.Errorf("Expected merged severities, got %v", main.Severities)
+	}
+
+	// Image override applies before the glob override
+	other := cfg.ForService(ServiceRef{Name: "db-other", Image: "postgres:16"})
+	if strings.Join(other.FieldOrder, ",") != "image,environment" {
+		t.Errorf("Expected glob override to win over image override, got %v", other.FieldOrder)
+	}
+
+	// Regular expression keys and partial alphabetization rules
+	worker := cfg.ForService(ServiceRef{Name: "worker-12"})
+	if worker.Alphabetization.Environment || !worker.Alphabetization.Volumes {
+		t.Errorf("Unexpected alphabetization rules: %+v", worker.Alphabetization)
+	}
+	if cfg.ForService(ServiceRef{Name: "worker-x"}).Alphabetization.Environment != true {
+		t.Error("Regular expression should not match worker-x")
+	}
+
+	// Label overrides
+	api := cfg.ForService(ServiceRef{Name: "db-api", Labels: map[string]string{"com.example.tier": "backend"}})
+	if !api.Strict {
+		t.Error("Expected the glob override to win over the label override")
+	}
+	cfg.Strict = true
+	if !cfg.ForService(ServiceRef{Name: "api", Labels: map[string]string{"com.example.tier": "frontend"}}).Strict {
+		t.Error("Label override should not match frontend")
+	}
+	if cfg.ForService(ServiceRef{Name: "api", Labels: map[string]string{"com.example.tier": "backend"}}).Strict {
+		t.Error("Label override should match backend")
+	}
+	cfg.Strict = false
+
+	// The global configuration is unchanged
+	if cfg.Strict || len(cfg.RequiredFields) != 0 || len(cfg.FieldOrder) != len(DefaultFieldOrder) {
+		t.Error("ForService should not modify the configuration")
+	}
+
+	// Lookups by name see pattern overrides
+	if len(cfg.GetFieldOrder("db-cache")) != 2 {
+		t.Errorf("Expected glob override for db-cache, got %v", cfg.GetFieldOrder("db-cache"))
+	}
+	unmatched := cfg.UnmatchedOverrides([]ServiceRef{{Name: "db-main"}, {Name: "worker-1"}})
+	if strings.Join(unmatched, ",") != "backend,postgres" {
+		t.Errorf("Unexpected unmatched overrides: %v", unmatched)
+	}
+}
+
+func TestLoadFromFile_OverrideErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		contains string
+	}{
+		{"invalid regexp", "service_overrides:\n  \"/worker-(/\":\n    strict: true\n", "invalid regular expression"},
+		{"invalid severity", "severities:\n  order: fatal\n", `invalid severity "fatal" for severities.order`},
+		{"invalid override severity", "service_overrides:\n  db:\n    severities:\n      order: loud\n", "service_overrides.db.severities.order"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configPath := filepath.Join(tmpDir, "config.yaml")
+			if err := os.WriteFile(configPath, []byte(test.content), 0644); err != nil {
+				t.Fatalf("Failed to create test config: %v", err)
+			}
+			_, err := LoadFromFile(configPath)
+			if err == nil || !strings.Contains(err.Error(), test.contains) {
+				t.Errorf("Expected error containing %q, got %v", test.contains, err)
+			}
+		})
+	}
+}
+
+func TestForFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `
+strict: true
+overrides:
+  - files: ["docker-compose.override.yml"]
+    strict: false
+    severities:
+      order: "off"
+  - files: ["dev/**"]
+    field_order: [image, container_name]
+  - files: ["dev/legacy/*.yml"]
+    alphabetization:
+      environment: false
+`
+	configPath := filepath.Join(tmpDir, ".compose-validator.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	legacy, err := cfg.ForFile(filepath.Join(tmpDir, "dev", "legacy", "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(legacy.FieldOrder, ",") != "image,container_name" || legacy.Alphabetization.Environment {
+		t.Errorf("Expected both dev overrides, got %v %+v", legacy.FieldOrder, legacy.Alphabetization)
+	}
+	if !legacy.Strict || !legacy.Alphabetization.Volumes {
+		t.Error("Settings not set by the overrides should be kept")
+	}
+
+	dev, err := cfg.ForFile(filepath.Join(tmpDir, "dev", "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(dev.FieldOrder) != 2 || !dev.Alphabetization.Environment {
+		t.Errorf("Expected only the dev/** override, got %v %+v", dev.FieldOrder, dev.Alphabetization)
+	}
+
+	// Globs without a slash match the file name anywhere
+	local, err := cfg.ForFile(filepath.Join(tmpDir, "stacks", "docker-compose.override.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if local.Strict || local.Severity("order") != "off" {
+		t.Errorf("Expected override for docker-compose.override.yml, got strict=%v severities=%v", local.Strict, local.Severities)
+	}
+
+	prod, err := cfg.ForFile(filepath.Join(tmpDir, "prod", "docker-compose.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prod != cfg {
+		t.Error("Expected the configuration itself when no override matches")
+	}
+	if !cfg.Strict || len(cfg.FieldOrder) != len(DefaultFieldOrder) {
+		t.Error("ForFile should not modify the configuration")
+	}
+}
+
+func TestForFile_Hierarchy(t *testing.T) {
+	tmpDir := t.TempDir()
+	appDir := filepath.Join(tmpDir, "app")
+	if err := os.MkdirAll(appDir, 0755); err != nil {
+		t.Fatalf("Failed to create dirs: %v", err)
+	}
+
+	configs := map[string]string{
+		tmpDir: "root: true\noverrides:\n  - files: [\"**/*.dev.yml\"]\n    strict: true\n",
+		appDir: "overrides:\n  - files: [\"test/**\"]\n    format:\n      enabled: true\n",
+	}
+	for dir, content := range configs {
+		if err := os.WriteFile(filepath.Join(dir, ".compose-validator.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create config: %v", err)
+		}
+	}
+
+	cfg, err := LoadForFile(filepath.Join(appDir, "test", "compose.dev.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cfg.Overrides) != 2 {
+		t.Fatalf("Expected overrides from both configs, got %d", len(cfg.Overrides))
+	}
+
+	fileCfg, err := cfg.ForFile(filepath.Join(appDir, "test", "compose.dev.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !fileCfg.Strict || !fileCfg.Format.Enabled {
+		t.Errorf("Expected both overrides to apply, got strict=%v format=%v", fileCfg.Strict, fileCfg.Format.Enabled)
+	}
+
+	// Globs of the nested config are relative to its directory
+	fileCfg, err = cfg.ForFile(filepath.Join(tmpDir, "test", "compose.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if fileCfg.Format.Enabled {
+		t.Error("Nested override should not match outside its directory")
+	}
+}
+
+func TestLoadFromFile_PathOverrideErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		contains string
+	}{
+		{"unknown key", "overrides:\n  - files: [dev/**]\n    strcit: true\n", `unknown field "strcit"`},
+		{"missing files", "overrides:\n  - strict: true\n", "overrides entry needs a files list"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configPath := filepath.Join(tmpDir, "config.yaml")
+			if err := os.WriteFile(configPath, []byte(test.content), 0644); err != nil {
+				t.Fatalf("Failed to create test config: %v", err)
+			}
+			_, err := LoadFromFile(configPath)
+			if err == nil || !strings.Contains(err.Error(), test.contains) {
+				t.Errorf("Expected error containing %q, got %v", test.contains, err)
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	files := map[string]string{
+		root: "root: true\nextends: strict\n",
+		sub: `
+service_overrides:
+  web:
+    strict: false
+overrides:
+  - files: ["*.prod.yml"]
+    alphabetization:
+      volumes: false
+`,
+	}
+	for dir, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, ".compose-validator.yaml"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config: %v", err)
+		}
+	}
+
+	cfg, err := LoadForDir(sub)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	rootConfig := filepath.Join(root, ".compose-validator.yaml")
+	subConfig := filepath.Join(sub, ".compose-validator.yaml")
+	expected := []string{"preset strict", rootConfig, subConfig}
+	if strings.Join(cfg.Sources, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected sources %v, got %v", expected, cfg.Sources)
+	}
+
+	prod, err := cfg.ForFile(filepath.Join(sub, "docker-compose.prod.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	web := prod.ForService(ServiceRef{Name: "web"})
+	expected = append(expected, subConfig+": overrides for *.prod.yml", "service_overrides.web")
+	if strings.Join(web.Sources, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected sources %v, got %v", expected, web.Sources)
+	}
+	if len(cfg.Sources) != 3 {
+		t.Errorf("Resolving a file or service should not change the sources of the config, got %v", cfg.Sources)
+	}
+
+	if sources := NewDefaultConfig().Sources; len(sources) != 0 {
+		t.Errorf("Expected no sources for the defaults, got %v", sources)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `
+strict: true
+service_overrides:
+  web:
+    strict: false
+overrides:
+  - files: ["*.prod.yml"]
+    alphabetization:
+      volumes: false
+`
+	configPath := filepath.Join(tmpDir, ".compose-validator.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := cfg.Encode("yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "# Sources:\n#   "+configPath+"\n") {
+		t.Errorf("Expected the sources in a leading comment, got:\n%s", data)
+	}
+
+	// The YAML output loads back as the same configuration
+	printed := filepath.Join(tmpDir, "printed.yaml")
+	if err := os.WriteFile(printed, data, 0644); err != nil {
+		t.Fatalf("Failed to write printed config: %v", err)
+	}
+	reloaded, err := LoadFromFile(printed)
+	if err != nil {
+		t.Fatalf("Printed config does not load: %v\n%s", err, data)
+	}
+	if !reloaded.Strict || reloaded.ServiceOverrides["web"].Strict == nil || len(reloaded.Overrides) != 1 {
+		t.Errorf("Printed config lost settings:\n%s", data)
+	}
+	prod, err := reloaded.ForFile(filepath.Join(tmpDir, "docker-compose.prod.yml"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if prod.Alphabetization.Volumes {
+		t.Errorf("Printed overrides block lost its settings:\n%s", data)
+	}
+
+	data, err = cfg.Encode("json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var decoded struct {
+		Sources []string               `json:"sources"`
+		Config  map[string]interface{} `json:"config"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, data)
+	}
+	if len(decoded.Sources) != 1 || decoded.Config["strict"] != true {
+		t.Errorf("Unexpected JSON output:\n%s", data)
+	}
+
+	if _, err := cfg.Encode("toml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestEncode_ResolvesExtends(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `
+root: true
+extends: strict
+unlisted_order: alphabetical
+`
+	configPath := filepath.Join(tmpDir, ".compose-validator.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	data, err := cfg.Encode("yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "extends:") || strings.Contains(string(data), "root:") {
+		t.Errorf("Expected extends and root to be left out:\n%s", data)
+	}
+
+	printed := filepath.Join(tmpDir, "printed.yaml")
+	if err := os.WriteFile(printed, data, 0644); err != nil {
+		t.Fatalf("Failed to write printed config: %v", err)
+	}
+	reloaded, err := LoadFromFile(printed)
+	if err != nil {
+		t.Fatalf("Printed config does not load: %v\n%s", err, data)
+	}
+
+	if reloaded.Extends != "" || reloaded.Root {
+		t.Errorf("Printed config should not extend or stop at anything:\n%s", data)
+	}
+
+	// Empty lists load back as empty rather than nil, so compare the
+	// settings as they are written
+	reloaded.Sources = cfg.Sources
+	again, err := reloaded.Encode("yaml")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("Printed config loads as a different configuration:\n%s\nreloaded:\n%s", data, again)
+	}
+}
+
+func TestJSONSchema_Descriptions(t *testing.T) {
+	keys := make(map[string]bool)
+	seen := make(map[reflect.Type]bool)
+	var walk func(reflect.Type)
+	walk = func(typ reflect.Type) {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			walk(typ.Elem())
+			return
+		case reflect.Struct:
+		default:
+			return
+		}
+		if seen[typ] {
+			return
+		}
+		seen[typ] = true
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if key == "" || key == "-" {
+				continue
+			}
+			keys[typ.Name()+"."+key] = true
+			walk(field.Type)
+		}
+	}
+	walk(reflect.TypeOf(Config{}))
+	walk(reflect.TypeOf(FieldGroup{}))
+
+	for key := range keys {
+		if schemaDescriptions[key] == "" {
+			t.Errorf("Config key %s has no schema description", key)
+		}
+	}
+	for key := range schemaDescriptions {
+		if !keys[key] {
+			t.Errorf("Schema description for %s matches no config key", key)
+		}
+	}
+	for key := range schemaEnums {
+		if !keys[key] {
+			t.Errorf("Schema enum for %s matches no config key", key)
+		}
+	}
+}
+
+func TestJSONSchema_UpToDate(t *testing.T) {
+	generated, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+	shipped, err := os.ReadFile(filepath.Join("..", "..", "schema", "compose-validator.schema.json"))
+	if err != nil {
+		t.Fatalf("Failed to read shipped schema: %v", err)
+	}
+	if string(generated) != string(shipped) {
+		t.Error("schema/compose-validator.schema.json is out of date; regenerate it with: go run ./cmd/compose-validator schema > schema/compose-validator.schema.json")
+	}
+}
+
+func TestJSONSchema_Validate(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource("schema.json", bytes.NewReader(data)); err != nil {
+		t.Fatalf("Failed to load schema: %v", err)
+	}
+	schema, err := compiler.Compile("schema.json")
+	if err != nil {
+		t.Fatalf("Failed to compile schema: %v", err)
+	}
+
+	validate := func(content []byte) error {
+		converted, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			t.Fatalf("Failed to convert YAML: %v", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(converted))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err != nil {
+			t.Fatalf("Failed to decode JSON: %v", err)
+		}
+		if value == nil {
+			// A config file with only comments sets nothing
+			return nil
+		}
+		return schema.Validate(value)
+	}
+
+	defaults, err := NewDefaultConfig().Encode("yaml")
+	if err != nil {
+		t.Fatalf("Failed to encode defaults: %v", err)
+	}
+	valid := map[string][]byte{"defaults": defaults}
+	for _, name := range []string{"default", "strict", "security"} {
+		preset, _ := presetData(name)
+		valid["preset "+name] = preset
+	}
+	valid["example"] = []byte(`
+extends: strict
+field_order:
+  - group: identity
+    fields: [container_name, image]
+  - x-*
+  - "*"
+unlisted_order: alphabetical
+severities:
+  order: warning
+service_overrides:
+  databases:
+    images: ["postgres:*"]
+    alphabetization:
+      volumes: false
+    strict: false
+overrides:
+  - files: ["dev/**"]
+    style:
+      environment: map
+`)
+	for name, content := range valid {
+		if err := validate(content); err != nil {
+			t.Errorf("Expected %s to be valid: %v", name, err)
+		}
+	}
+
+	invalid := map[string]string{
+		"unknown key":           "alphabetisation:\n  volumes: true\n",
+		"wrong type":            "strict: yes please\n",
+		"unknown value":         "unlisted_order: random\n",
+		"unknown style":         "style:\n  environment: lists\n",
+		"unknown severity":      "severities:\n  order: fatal\n",
+		"unknown severity type": "severities:\n  ordering: warning\n",
+		"group without fields":  "field_order:\n  - group: identity\n",
+		"overrides no files":    "overrides:\n  - strict: true\n",
+		"nested unknown key":    "service_overrides:\n  web:\n    stric: true\n",
+	}
+	for name, content := range invalid {
+		if err := validate([]byte(content)); err == nil {
+			t.Errorf("Expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestEnvSettings(t *testing.T) {
+	settings, err := EnvSettings([]string{
+		"PATH=/usr/bin",
+		"COMPOSE_VALIDATOR_STRICT=true",
+		"COMPOSE_VALIDATOR_ALPHABETIZATION_VOLUMES=false",
+		"COMPOSE_VALIDATOR_SECURITY_SECURITY_OPT_ENABLED=false",
+		"COMPOSE_VALIDATOR_FORMAT_BLANK_LINES_BETWEEN_SERVICES=2",
+		"COMPOSE_VALIDATOR_SEVERITIES_ORDER=warning",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"alphabetization.volumes=false",
+		"format.blank_lines_between_services=2",
+		"security.security_opt.enabled=false",
+		"severities.order=warning",
+		"strict=true",
+	}
+	if len(settings) != len(expected) {
+		t.Fatalf("Expected %d settings, got %+v", len(expected), settings)
+	}
+	for i, setting := range settings {
+		if got := setting.Key + "=" + setting.Value; got != expected[i] {
+			t.Errorf("Setting %d: expected %s, got %s", i, expected[i], got)
+		}
+	}
+
+	for _, name := range []string{"COMPOSE_VALIDATOR_STRCT", "COMPOSE_VALIDATOR_SERVICE_OVERRIDES_WEB_STRICT", "COMPOSE_VALIDATOR_"} {
+		if _, err := EnvSettings([]string{name + "=true"}); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected an error naming %s, got %v", name, err)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `
+strict: true
+exclude: ["vendor/**"]
+alphabetization:
+  environment: false
+`
+	configPath := filepath.Join(tmpDir, ".compose-validator.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if same, err := cfg.Apply(nil); err != nil || same != cfg {
+		t.Errorf("Expected the configuration itself without settings, got %v", err)
+	}
+
+	env, err := EnvSettings([]string{"COMPOSE_VALIDATOR_STRICT=false", "COMPOSE_VALIDATOR_ALPHABETIZATION_VOLUMES=false"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	flags := make([]Setting, 0)
+	for _, flag := range []string{"strict=true", "field_order=[image, container_name]", `exclude=["*.bak.yml"]`, "severities.order=warning"} {
+		setting, err := ParseSetting(flag)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		flags = append(flags, setting)
+	}
+
+	applied, err := cfg.Apply(append(env, flags...))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !applied.Strict {
+		t.Error("Expected flags to win over the environment")
+	}
+	if applied.Alphabetization.Volumes || applied.Alphabetization.Environment || !applied.Alphabetization.Labels {
+		t.Errorf("Expected only volumes to change, got %+v", applied.Alphabetization)
+	}
+	if strings.Join(applied.FieldOrder, ",") != "image,container_name" {
+		t.Errorf("Expected field order from the flag, got %v", applied.FieldOrder)
+	}
+	if strings.Join(applied.Exclude, ",") != "vendor/**,*.bak.yml" {
+		t.Errorf("Expected exclude patterns to be added, got %v", applied.Exclude)
+	}
+	if applied.Severity("order") != "warning" {
+		t.Errorf("Expected order severity warning, got %s", applied.Severity("order"))
+	}
+	if len(applied.Sources) != 7 || applied.Sources[6] != "--set severities.order=warning" {
+		t.Errorf("Expected the settings in the sources, got %v", applied.Sources)
+	}
+	if !cfg.Strict || cfg.Alphabetization.Volumes == false || len(cfg.Exclude) != 1 {
+		t.Error("Applying settings should not change the original configuration")
+	}
+
+	for _, flag := range []string{"strct=true", "unlisted_order=random", "strict=maybe", "format..indent=2"} {
+		setting, err := ParseSetting(flag)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := cfg.Apply([]Setting{setting}); err == nil || !strings.Contains(err.Error(), "--set "+flag) {
+			t.Errorf("Expected an error naming --set %s, got %v", flag, err)
+		}
+	}
+	for _, flag := range []string{"strict", "=true"} {
+		if _, err := ParseSetting(flag); err == nil {
+			t.Errorf("Expected an error for %q", flag)
+		}
+	}
+}
+
+func TestCompareKeys(t *testing.T) {
+	tests := []struct {
+		mode     string
+		a, b     string
+		expected int
+	}{
+		{CompareCaseInsensitive, "apple", "Banana", -1},
+		{CompareCaseInsensitive, "API", "api", 0},
+		{CompareCaseInsensitive, "APP_10", "APP_2", -1},
+		{CompareCaseSensitive, "Apple", "apple", -1},
+		{CompareCaseSensitive, "apple", "Banana", -1},
+		{CompareNatural, "APP_2", "app_10", -1},
+		{CompareNatural, "v1.10", "v1.9", 1},
+		{CompareNatural, "disk007", "disk7", 0},
+		{CompareNatural, "a1", "a1b", -1},
+		{CompareByte, "Zebra", "apple", -1},
+		{CompareByte, "apple", "apple", 0},
+		{"", "b", "A", 1},
+	}
+
+	for _, test := range tests {
+		if got := CompareKeys(test.mode, test.a, test.b); got != test.expected {
+			t.Errorf("CompareKeys(%q, %q, %q) = %d, expected %d", test.mode, test.a, test.b, got, test.expected)
+		}
+	}
+}
+
+func TestComparison(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `
+alphabetization:
+  comparison:
+    environment: natural
+service_overrides:
+  legacy:
+    alphabetization:
+      comparison:
+        labels: byte
+`
+	configPath := filepath.Join(tmpDir, ".compose-validator.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test config: %v", err)
+	}
+	cfg, err := LoadFromFile(configPath)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cfg.Comparison("environment") != CompareNatural || cfg.Comparison("volumes") != CompareCaseInsensitive {
+		t.Errorf("Unexpected comparisons: %+v", cfg.Alphabetization.Comparison)
+	}
+	legacy := cfg.ForService(ServiceRef{Name: "legacy"})
+	if legacy.Comparison("labels") != CompareByte || legacy.Comparison("environment") != CompareNatural {
+		t.Errorf("Unexpected comparisons for legacy: %+v", legacy.Alphabetization.Comparison)
+	}
+	if (&Config{}).Comparison("labels") != CompareCaseInsensitive {
+		t.Error("Expected case-insensitive comparison when none is set")
+	}
+
+	invalid := map[string]string{
+		"alphabetization:\n  comparison:\n    volumes: locale\n":                                       `invalid comparison "locale" for alphabetization.comparison.volumes`,
+		"service_overrides:\n  web:\n    alphabetization:\n      comparison:\n        labels: ascii\n": `service_overrides.web.alphabetization.comparison.labels`,
+	}
+	for content, expected := range invalid {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test config: %v", err)
+		}
+		if _, err := LoadFromFile(configPath); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected error containing %q, got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Encode writes the configuration in the given format. YAML output lists the
+// sources in a leading comment and can be used as a config file; JSON output
+// is an object with the sources and the configuration. The output holds the
+// resolved settings, so extends and root are left out.
+func (c *Config) Encode(format string) ([]byte, error) {
+	resolved := *c
+	resolved.Extends, resolved.Root = "", false
+	c = &resolved
+
+	sources := c.Sources
+	if sources == nil {
+		sources = []string{}
+	}
+
+	switch format {
+	case "yaml", "yml", "":
+		var buf bytes.Buffer
+		if len(sources) == 0 {
+			buf.WriteString("# Sources: built-in defaults\n")
+		} else {
+			buf.WriteString("# Sources:\n")
+			for _, source := range sources {
+				fmt.Fprintf(&buf, "#   %s\n", source)
+			}
+		}
+		data, err := yaml.Marshal(c)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+		return buf.Bytes(), nil
+
+	case "json":
+		data, err := yaml.MarshalWithOptions(yaml.MapSlice{
+			{Key: "sources", Value: sources},
+			{Key: "config", Value: c},
+		}, yaml.JSON())
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n")
+		return buf.Bytes(), nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q (expected yaml or json)", format)
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// presetFiles holds the built-in configs that extends can name
+//
+//go:embed presets/*.yaml
+var presetFiles embed.FS
+
+// loadBase applies the config named by extends on top of base: a built-in
+// preset such as "strict", or a file path relative to dir
+func loadBase(extends, dir string, base *Config, chain []string) (*Config, error) {
+	if data, ok := presetData(extends); ok {
+		key := "preset " + extends
+		if err := checkExtendsCycle(key, chain); err != nil {
+			return nil, err
+		}
+		cfg, err := loadConfig(data, dir, base, append(chain, key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load preset %s: %w", extends, err)
+		}
+		cfg.addSource(key)
+		return cfg, nil
+	}
+
+	file := extends
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	key := file
+	if abs, err := filepath.Abs(file); err == nil {
+		key = abs
+	}
+	if err := checkExtendsCycle(key, chain); err != nil {
+		return nil, err
+	}
+	return loadFile(file, base, chain)
+}
+
+// presetData returns the built-in preset with the given name
+func presetData(name string) ([]byte, bool) {
+	if name == "" || strings.ContainsAny(name, `/\.`) {
+		return nil, false
+	}
+	data, err := presetFiles.ReadFile(path.Join("presets", name+".yaml"))
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// checkExtendsCycle reports a config that extends itself, directly or not
+func checkExtendsCycle(key string, chain []string) error {
+	for i, seen := range chain {
+		if seen == key {
+			return fmt.Errorf("extends cycle: %s", strings.Join(append(chain[i:], key), " -> "))
+		}
+	}
+	return nil
+}
+
+// mergeExtended applies the merge rules that decoding on top of the base
+// config, extended or from a parent directory, does not: exclude patterns
+// and overrides blocks are added to the inherited ones, and
+// service_overrides are merged per service, with the keys a service sets
+// replacing those of the base. Other settings, including field_order, are
+// replaced key by key. Overrides blocks match paths relative to dir.
+func mergeExtended(data []byte, dir string, cfg *Config, inherited Config) error {
+	var raw struct {
+		Exclude          []string               `yaml:"exclude"`
+		ServiceOverrides map[string]interface{} `yaml:"service_overrides"`
+		Overrides        []interface{}          `yaml:"overrides"`
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if raw.Exclude != nil {
+		exclude := append([]string{}, inherited.Exclude...)
+		for _, pattern := range raw.Exclude {
+			if !containsString(exclude, pattern) {
+				exclude = append(exclude, pattern)
+			}
+		}
+		cfg.Exclude = exclude
+	}
+
+	if raw.ServiceOverrides != nil {
+		overrides := make(map[string]ServiceOverride, len(inherited.ServiceOverrides)+len(raw.ServiceOverrides))
+		for name, override := range inherited.ServiceOverrides {
+			overrides[name] = override
+		}
+		for name, node := range raw.ServiceOverrides {
+			override := overrides[name]
+			encoded, err := yaml.Marshal(node)
+			if err != nil {
+				return err
+			}
+			if err := yaml.Unmarshal(encoded, &override); err != nil {
+				return fmt.Errorf("service_overrides.%s: %w", name, err)
+			}
+			overrides[name] = override
+		}
+		cfg.ServiceOverrides = overrides
+	}
+
+	if raw.Overrides != nil {
+		for i := range cfg.Overrides {
+			cfg.Overrides[i].dir = dir
+		}
+		cfg.Overrides = append(append([]PathOverride{}, inherited.Overrides...), cfg.Overrides...)
+	}
+
+	return nil
+}
+
+// containsString checks if a string is in the list
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
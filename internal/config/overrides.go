@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ServiceRef describes a service for matching service_overrides
+type ServiceRef struct {
+	Name   string
+	Image  string
+	Labels map[string]string
+}
+
+// Kinds of override matches, from least to most specific
+const (
+	matchNone = iota
+	matchAttributes
+	matchPattern
+	matchName
+)
+
+// ForService returns the configuration for a single service, with the
+// matching service_overrides applied. Overrides matching by image or labels
+// are applied first, then those matching the name by glob or regular
+// expression, then the one for the exact name, so the most specific setting
+// wins. Overrides of the same kind are applied in key order.
+func (c *Config) ForService(ref ServiceRef) *Config {
+	service := *c
+	service.ServiceOverrides = nil
+	if len(c.ServiceOverrides) == 0 {
+		return &service
+	}
+
+	type match struct {
+		key  string
+		kind int
+	}
+	matches := make([]match, 0)
+	for key, override := range c.ServiceOverrides {
+		if kind := matchOverride(key, override, ref); kind != matchNone {
+			matches = append(matches, match{key, kind})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].kind != matches[j].kind {
+			return matches[i].kind < matches[j].kind
+		}
+		return matches[i].key < matches[j].key
+	})
+
+	for _, m := range matches {
+		service.applyOverride(c.ServiceOverrides[m.key])
+		service.Sources = append(append([]string{}, service.Sources...), "service_overrides."+m.key)
+	}
+	return &service
+}
+
+// applyOverride changes the settings an override sets. Required and
+// forbidden fields are added; the other settings are replaced.
+func (c *Config) applyOverride(override ServiceOverride) {
+	if len(override.FieldOrder) > 0 {
+		c.FieldOrder = override.FieldOrder
+		c.FieldGroups = override.FieldGroups
+	}
+	c.RequiredFields = appendUnique(append([]string{}, c.RequiredFields...), override.RequiredFields...)
+	c.ForbiddenFields = appendUnique(append([]string{}, c.ForbiddenFields...), override.ForbiddenFields...)
+
+	if v := override.Alphabetization.Environment; v != nil {
+		c.Alphabetization.Environment = *v
+	}
+	if v := override.Alphabetization.Volumes; v != nil {
+		c.Alphabetization.Volumes = *v
+	}
+	if v := override.Alphabetization.Labels; v != nil {
+		c.Alphabetization.Labels = *v
+	}
+	if mode := override.Alphabetization.Comparison.Environment; mode != "" {
+		c.Alphabetization.Comparison.Environment = mode
+	}
+	if mode := override.Alphabetization.Comparison.Volumes; mode != "" {
+		c.Alphabetization.Comparison.Volumes = mode
+	}
+	if mode := override.Alphabetization.Comparison.Labels; mode != "" {
+		c.Alphabetization.Comparison.Labels = mode
+	}
+	if override.Strict != nil {
+		c.Strict = *override.Strict
+	}
+
+	if len(override.Severities) > 0 {
+		severities := make(map[string]string, len(c.Severities)+len(override.Severities))
+		for rule, severity := range c.Severities {
+			severities[rule] = severity
+		}
+		for rule, severity := range override.Severities {
+			severities[rule] = severity
+		}
+		c.Severities = severities
+	}
+}
+
+// matchOverride returns how an override applies to a service, or matchNone
+func matchOverride(key string, override ServiceOverride, ref ServiceRef) int {
+	if len(override.Images) > 0 || len(override.Labels) > 0 {
+		if matchesAttributes(override, ref) {
+			return matchAttributes
+		}
+		return matchNone
+	}
+
+	if key == ref.Name {
+		return matchName
+	}
+	if pattern, ok := overrideRegexp(key); ok {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(ref.Name) {
+			return matchPattern
+		}
+		return matchNone
+	}
+	if strings.ContainsAny(key, "*?[") {
+		if matched, _ := filepath.Match(key, ref.Name); matched {
+			return matchPattern
+		}
+	}
+	return matchNone
+}
+
+// matchesAttributes checks if a service has a matching image, if the
+// override lists images, and carries all labels it lists
+func matchesAttributes(override ServiceOverride, ref ServiceRef) bool {
+	if len(override.Images) > 0 {
+		policy := FieldPolicy{Images: override.Images}
+		if !policy.Matches("", ref.Image) {
+			return false
+		}
+	}
+	for key, pattern := range override.Labels {
+		value, ok := ref.Labels[key]
+		if !ok {
+			return false
+		}
+		if matched, _ := filepath.Match(pattern, value); pattern != "" && !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// overrideRegexp returns the expression of a key written as /expression/
+func overrideRegexp(key string) (string, bool) {
+	if len(key) > 2 && strings.HasPrefix(key, "/") && strings.HasSuffix(key, "/") {
+		return key[1 : len(key)-1], true
+	}
+	return "", false
+}
+
+// Severity returns how violations of a rule are reported: error, warning or
+// off. Rules without a configured severity are errors.
+func (c *Config) Severity(rule string) string {
+	if severity := c.Severities[rule]; severity != "" {
+		return severity
+	}
+	return "error"
+}
+
+// checkOverrides validates regular expression keys, severities and
+// comparison modes
+func checkOverrides(cfg *Config) error {
+	if err := checkSeverities(cfg.Severities, "severities"); err != nil {
+		return err
+	}
+	if err := checkComparisons(cfg.Alphabetization.Comparison, "alphabetization.comparison"); err != nil {
+		return err
+	}
+	for key, override := range cfg.ServiceOverrides {
+		if pattern, ok := overrideRegexp(key); ok {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("service_overrides: invalid regular expression %s: %w", key, err)
+			}
+		}
+		if err := checkSeverities(override.Severities, "service_overrides."+key+".severities"); err != nil {
+			return err
+		}
+		if err := checkComparisons(override.Alphabetization.Comparison, "service_overrides."+key+".alphabetization.comparison"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// violationTypes are the violation types severities can be set for
+var violationTypes = []string{
+	"order", "alphabetization", "port", "duplicate", "container_name", "image",
+	"security", "required_field", "forbidden_field", "secret", "schema",
+	"unknown_field", "deprecated", "style", "quoting", "format",
+}
+
+// checkSeverities checks that every key is a violation type and every
+// severity is error, warning or off
+func checkSeverities(severities map[string]string, name string) error {
+	for rule, severity := range severities {
+		if !containsString(violationTypes, rule) {
+			return fmt.Errorf("unknown violation type %q in %s (expected one of %s)", rule, name, strings.Join(violationTypes, ", "))
+		}
+		switch severity {
+		case "error", "warning", "off":
+		default:
+			return fmt.Errorf("invalid severity %q for %s.%s (expected error, warning or off)", severity, name, rule)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// PathOverride adjusts settings for the files matching its path globs. Any
+// config key may be set in the block; the settings are applied on top of the
+// configuration with the same merge rules as extends.
+type PathOverride struct {
+	Files []string `yaml:"files"`
+	// settings is the YAML of the block without files
+	settings []byte
+	// dir is the directory of the config file defining the block, which
+	// globs are matched from
+	dir string
+	// source is the config file or preset defining the block
+	source string
+}
+
+// UnmarshalYAML decodes the files list and keeps the other keys as settings.
+// The settings are decoded as a config, so unknown keys are reported.
+func (o *PathOverride) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var block struct {
+		Files  []string `yaml:"files"`
+		Config `yaml:",inline"`
+	}
+	if err := unmarshal(&block); err != nil {
+		return err
+	}
+	if len(block.Files) == 0 {
+		return fmt.Errorf("overrides entry needs a files list")
+	}
+
+	var raw map[string]interface{}
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	delete(raw, "files")
+	settings, err := yaml.Marshal(raw)
+	if err != nil {
+		return err
+	}
+
+	o.Files = block.Files
+	o.settings = settings
+	return nil
+}
+
+// MarshalYAML writes the files list followed by the settings, so a printed
+// block reads like the one in the config file
+func (o PathOverride) MarshalYAML() (interface{}, error) {
+	block := yaml.MapSlice{{Key: "files", Value: o.Files}}
+	var settings yaml.MapSlice
+	if err := yaml.Unmarshal(o.settings, &settings); err != nil {
+		return nil, err
+	}
+	return append(block, settings...), nil
+}
+
+// Matches checks if a file matches one of the globs. A glob without a slash
+// matches the file name in any directory; other globs are matched against
+// the path relative to the config file, with ** standing for any number of
+// directories.
+func (o PathOverride) Matches(file string) bool {
+	rel := filepath.ToSlash(filepath.Clean(file))
+	if abs, err := filepath.Abs(file); err == nil && o.dir != "" {
+		if dir, err := filepath.Abs(o.dir); err == nil {
+			if r, err := filepath.Rel(dir, abs); err == nil && !strings.HasPrefix(r, "..") {
+				rel = filepath.ToSlash(r)
+			}
+		}
+	}
+
+	for _, pattern := range o.Files {
+		pattern = strings.TrimPrefix(pattern, "./")
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(rel)); matched {
+				return true
+			}
+			continue
+		}
+		if matchPathGlob(strings.Split(pattern, "/"), strings.Split(rel, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPathGlob matches path segments against glob segments, where a "**"
+// segment matches any number of path segments
+func matchPathGlob(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchPathGlob(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if matched, _ := path.Match(pattern[0], segments[0]); !matched {
+		return false
+	}
+	return matchPathGlob(pattern[1:], segments[1:])
+}
+
+// ForFile returns the configuration for a compose file, with the overrides
+// blocks matching its path applied in order
+func (c *Config) ForFile(file string) (*Config, error) {
+	cfg := c
+	for _, override := range c.Overrides {
+		if !override.Matches(file) {
+			continue
+		}
+		base := *cfg
+		applied, err := loadConfig(override.settings, override.dir, &base, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply overrides for %s: %w", file, err)
+		}
+		applied.Sources = append(append([]string{}, applied.Sources...),
+			fmt.Sprintf("%s: overrides for %s", override.source, strings.Join(override.Files, ", ")))
+		cfg = applied
+	}
+	return cfg, nil
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// schemaDescriptions documents every config key in the JSON schema, keyed by
+// Go type and YAML key. Tests check that each key has a description.
+var schemaDescriptions = map[string]string{
+	"Config.root":              "Stop looking for config files in parent directories",
+	"Config.extends":           "Config file to build on, relative to this file, or a built-in preset: default, strict or security",
+	"Config.field_order":       "Order of service fields; entries are field names, patterns such as x-*, the * placeholder for unlisted fields, or named groups",
+	"Config.group_separators":  "Require a blank line before the first field of each field group",
+	"Config.unlisted_order":    "Order of fields sharing a pattern or the * placeholder",
+	"Config.alphabetization":   "Fields whose entries must be alphabetized",
+	"Config.strict":            "Report fields not listed in field_order",
+	"Config.exclude":           "Glob patterns of compose files to skip; **/ matches in any directory",
+	"Config.service_overrides": "Settings for services matched by name, glob, /regular expression/, image or labels",
+	"Config.severities":        "How violations of each rule, such as order, are reported",
+	"Config.overrides":         "Settings for compose files matching path globs, applied in order",
+	"Config.ports":             "Host port conflict detection",
+	"Config.duplicates":        "Duplicate entry detection",
+	"Config.container_names":   "container_name checks",
+	"Config.images":            "Image reference policy",
+	"Config.security":          "Opt-in security hardening rule pack",
+	"Config.required_fields":   "Fields every service must define; dotted paths such as deploy.resources.limits are allowed",
+	"Config.forbidden_fields":  "Fields no service may define",
+	"Config.field_policies":    "Required and forbidden fields for services matching name globs or image patterns",
+	"Config.secrets":           "Plaintext secret detection in environment and labels",
+	"Config.schema_validation": "Validate files against the Compose Specification schema",
+	"Config.typos":             "Suggestions for misspelled service fields",
+	"Config.deprecations":      "Opt-in detection of obsolete Compose syntax",
+	"Config.style":             "How values are written",
+	"Config.format":            "Layout checks",
+
+	"FieldGroup.group":  "Name of the group",
+	"FieldGroup.fields": "Fields of the group, in order",
+
+	"AlphabetizationRules.environment": "Alphabetize environment variables",
+	"AlphabetizationRules.volumes":     "Alphabetize volumes by source path",
+	"AlphabetizationRules.labels":      "Alphabetize labels",
+	"AlphabetizationRules.comparison":  "How the entries of each field are compared",
+
+	"ComparisonModes.environment": "Comparison of environment variable names",
+	"ComparisonModes.volumes":     "Comparison of volume source paths",
+	"ComparisonModes.labels":      "Comparison of label keys",
+
+	"PortRules.check_conflicts": "Report services binding the same host IP, port and protocol",
+	"PortRules.across_files":    "Detect conflicts across all files checked in one run",
+
+	"DuplicateRules.keys":           "Report duplicate mapping keys",
+	"DuplicateRules.environment":    "Report environment variables set twice",
+	"DuplicateRules.labels":         "Report labels set twice",
+	"DuplicateRules.volume_targets": "Report volumes mounted at the same container path",
+
+	"ContainerNameRules.unique":       "Report container names used by more than one service",
+	"ContainerNameRules.across_files": "Also compare container names across all files checked in one run",
+	"ContainerNameRules.pattern":      "Regular expression container names must match in full; {project} and {service} stand for the project and service names",
+
+	"ImageRules.require_tag":          "Require an explicit image tag",
+	"ImageRules.disallow_latest":      "Report images tagged latest",
+	"ImageRules.require_digest":       "Require images pinned by digest",
+	"ImageRules.allowed_registries":   "Registry hosts images may be pulled from; docker.io stands for Docker Hub",
+	"ImageRules.allowed_repositories": "Glob patterns such as ghcr.io/myorg/* for allowed repositories",
+
+	"SecurityCheck.enabled": "Run this check",
+	"SecurityCheck.allow":   "Service name glob patterns exempt from this check",
+
+	"SecurityRules.enabled":                "Run the security rule pack",
+	"SecurityRules.privileged":             "Report privileged: true",
+	"SecurityRules.cap_add":                "Report dangerous added capabilities",
+	"SecurityRules.network_mode_host":      "Report network_mode: host",
+	"SecurityRules.pid_host":               "Report pid: host",
+	"SecurityRules.docker_socket":          "Report mounts of the Docker socket",
+	"SecurityRules.sensitive_mounts":       "Report writable bind mounts of sensitive host paths",
+	"SecurityRules.security_opt":           "Report security_opt entries disabling confinement",
+	"SecurityRules.missing_user":           "Report services without a user",
+	"SecurityRules.dangerous_capabilities": "Capabilities reported by the cap_add check",
+	"SecurityRules.sensitive_paths":        "Host paths that must not be bind mounted writable",
+
+	"SecretRules.enabled":           "Detect plaintext secrets",
+	"SecretRules.sensitive_keys":    "Key glob patterns that must not carry literal values",
+	"SecretRules.entropy_threshold": "Shannon entropy in bits per character above which long values are reported",
+	"SecretRules.min_length":        "Minimum length of values checked for entropy",
+	"SecretRules.allow":             "Key glob patterns that are never reported",
+
+	"TypoRules.enabled":      "Suggest the intended field for unknown service fields",
+	"TypoRules.max_distance": "Largest edit distance at which a suggestion is made",
+
+	"DeprecationRules.enabled":                 "Report obsolete Compose syntax",
+	"DeprecationRules.version":                 "Report the top-level version key",
+	"DeprecationRules.links":                   "Report links",
+	"DeprecationRules.external_links":          "Report external_links",
+	"DeprecationRules.volumes_from":            "Report volumes_from",
+	"DeprecationRules.container_name_replicas": "Report container_name on services with deploy.replicas",
+
+	"StyleRules.environment":        "Form of environment: list (KEY=value entries), map (KEY: value pairs) or consistent (the form of the first service in each file); empty allows both",
+	"StyleRules.labels":             "Form of labels: list, map or consistent; empty allows both",
+	"StyleRules.quote_ports":        "Require short-syntax port mappings to be quoted",
+	"StyleRules.quote_label_values": "Require label values to be quoted",
+	"StyleRules.quote_versions":     "Require version-like values such as 3.8 to be quoted",
+	"StyleRules.quote_style":        "Quote style to use: double or single; empty allows both",
+
+	"FormatRules.enabled":                      "Run the layout checks",
+	"FormatRules.indent":                       "Number of spaces per nesting level",
+	"FormatRules.sequence_indent":              "indented (entries indented under their key) or compact (dashes aligned with the key); empty allows both",
+	"FormatRules.trailing_whitespace":          "Report trailing whitespace",
+	"FormatRules.blank_lines_between_services": "Number of blank lines separating services; a negative value disables the check",
+	"FormatRules.final_newline":                "Require a single newline at the end of the file",
+
+	"FieldPolicy.services":         "Service name glob patterns the policy applies to",
+	"FieldPolicy.images":           "Image patterns the policy applies to",
+	"FieldPolicy.required_fields":  "Fields matching services must define",
+	"FieldPolicy.forbidden_fields": "Fields matching services must not define",
+
+	"ServiceOverride.images":           "Image patterns such as postgres:*; the override then matches by image instead of its key",
+	"ServiceOverride.labels":           "Labels services must carry, with glob patterns as values; the override then matches by labels instead of its key",
+	"ServiceOverride.field_order":      "Field order replacing the configured one",
+	"ServiceOverride.required_fields":  "Fields added to the required fields",
+	"ServiceOverride.forbidden_fields": "Fields added to the forbidden fields",
+	"ServiceOverride.alphabetization":  "Alphabetization rules to change; unset rules are kept",
+	"ServiceOverride.strict":           "Strict mode for matching services",
+	"ServiceOverride.severities":       "Severities to change for matching services",
+
+	"AlphabetizationOverride.environment": "Alphabetize environment variables",
+	"AlphabetizationOverride.volumes":     "Alphabetize volumes by source path",
+	"AlphabetizationOverride.labels":      "Alphabetize labels",
+	"AlphabetizationOverride.comparison":  "Comparison modes to change; unset fields are kept",
+
+	"PathOverride.files": "Path globs of the files the block applies to; globs without a slash match the file name, ** matches any number of directories",
+}
+
+// schemaEnums lists the allowed values of string keys, or of the values of
+// string maps
+var schemaEnums = map[string][]string{
+	"Config.unlisted_order":       {"original", "alphabetical"},
+	"Config.severities":           {"error", "warning", "off"},
+	"ServiceOverride.severities":  {"error", "warning", "off"},
+	"StyleRules.environment":      {"", "list", "map", "consistent"},
+	"StyleRules.labels":           {"", "list", "map", "consistent"},
+	"StyleRules.quote_style":      {"", "double", "single"},
+	"ComparisonModes.environment": comparisonModes,
+	"ComparisonModes.volumes":     comparisonModes,
+	"ComparisonModes.labels":      comparisonModes,
+	"FormatRules.sequence_indent": {"", "indented", "compact"},
+}
+
+// schemaKeys lists the allowed keys of maps
+var schemaKeys = map[string][]string{
+	"Config.severities":          violationTypes,
+	"ServiceOverride.severities": violationTypes,
+}
+
+// comparisonModes are the allowed comparison modes of alphabetized fields
+var comparisonModes = []string{"", CompareCaseInsensitive, CompareCaseSensitive, CompareNatural, CompareByte}
+
+// schemaRequired lists the keys an object must set
+var schemaRequired = map[string][]string{
+	"FieldGroup":   {"group", "fields"},
+	"PathOverride": {"files"},
+}
+
+var (
+	fieldOrderType   = reflect.TypeOf(FieldOrder{})
+	pathOverrideType = reflect.TypeOf(PathOverride{})
+)
+
+// JSONSchema returns a JSON schema for config files, generated from the
+// Config struct, with the defaults of NewDefaultConfig
+func JSONSchema() ([]byte, error) {
+	schema := structSchema(reflect.TypeOf(Config{}), reflect.ValueOf(*NewDefaultConfig()))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "compose-validator configuration"
+	schema["definitions"] = map[string]interface{}{
+		"override": pathOverrideSchema(),
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// structSchema describes the YAML keys of a struct. defaults is a value of
+// the struct holding the defaults, or the zero Value for none.
+func structSchema(t reflect.Type, defaults reflect.Value) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		var value reflect.Value
+		if defaults.IsValid() {
+			value = defaults.Field(i)
+		}
+		name := t.Name() + "." + key
+		schema := typeSchema(field.Type, value, name)
+		if description, ok := schemaDescriptions[name]; ok {
+			schema["description"] = description
+		}
+		properties[key] = schema
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if required, ok := schemaRequired[t.Name()]; ok {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// typeSchema describes a value of type t. name identifies the key holding
+// it, for looking up allowed values.
+func typeSchema(t reflect.Type, defaults reflect.Value, name string) map[string]interface{} {
+	switch {
+	case t == fieldOrderType:
+		group := structSchema(reflect.TypeOf(FieldGroup{}), reflect.Value{})
+		schema := map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"oneOf": []interface{}{map[string]interface{}{"type": "string"}, group}},
+		}
+		setDefault(schema, defaults)
+		return schema
+	case t == pathOverrideType:
+		return map[string]interface{}{"$ref": "#/definitions/override"}
+	}
+
+	var schema map[string]interface{}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem(), reflect.Value{}, name)
+	case reflect.Struct:
+		return structSchema(t, defaults)
+	case reflect.Bool:
+		schema = map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		schema = map[string]interface{}{"type": "integer"}
+	case reflect.Float64:
+		schema = map[string]interface{}{"type": "number"}
+	case reflect.String:
+		schema = map[string]interface{}{"type": "string"}
+		if enum, ok := schemaEnums[name]; ok {
+			schema["enum"] = enum
+		}
+	case reflect.Slice:
+		schema = map[string]interface{}{"type": "array", "items": typeSchema(t.Elem(), reflect.Value{}, name)}
+	case reflect.Map:
+		schema = map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem(), reflect.Value{}, name)}
+		if keys, ok := schemaKeys[name]; ok {
+			schema["propertyNames"] = map[string]interface{}{"enum": keys}
+		}
+	default:
+		schema = map[string]interface{}{}
+	}
+	setDefault(schema, defaults)
+	return schema
+}
+
+// setDefault records a default value, leaving out empty lists and maps
+func setDefault(schema map[string]interface{}, value reflect.Value) {
+	if !value.IsValid() {
+		return
+	}
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		if value.Len() == 0 {
+			return
+		}
+	}
+	schema["default"] = value.Interface()
+}
+
+// pathOverrideSchema describes an overrides block: its files list and any
+// config key
+func pathOverrideSchema() map[string]interface{} {
+	schema := structSchema(reflect.TypeOf(Config{}), reflect.Value{})
+	files := structSchema(pathOverrideType, reflect.Value{})
+	properties := schema["properties"].(map[string]interface{})
+	for key, property := range files["properties"].(map[string]interface{}) {
+		properties[key] = property
+	}
+	schema["required"] = files["required"]
+	return schema
+}
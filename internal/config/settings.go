@@ -0,0 +1,135 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// EnvPrefix starts the names of environment variables holding settings
+const EnvPrefix = "COMPOSE_VALIDATOR_"
+
+// Setting changes a single config key, from the environment or the command
+// line. Value is YAML, so lists and maps can be given in flow style.
+type Setting struct {
+	// Key is a dotted path such as alphabetization.volumes
+	Key   string
+	Value string
+	// Source names where the setting comes from, for errors and Sources
+	Source string
+}
+
+// ParseSetting parses a key=value setting given on the command line
+func ParseSetting(s string) (Setting, error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return Setting{}, fmt.Errorf("invalid setting %q (expected key=value)", s)
+	}
+	return Setting{Key: strings.TrimSpace(key), Value: value, Source: "--set " + s}, nil
+}
+
+// EnvSettings returns the settings in environment variables named after
+// config keys, such as COMPOSE_VALIDATOR_ALPHABETIZATION_VOLUMES for
+// alphabetization.volumes, sorted by name. environ holds KEY=value entries
+// as returned by os.Environ.
+func EnvSettings(environ []string) ([]Setting, error) {
+	settings := make([]Setting, 0)
+	for _, entry := range environ {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		key, ok := envKey(reflect.TypeOf(Config{}), strings.ToLower(strings.TrimPrefix(name, EnvPrefix)))
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s does not name a config key", name)
+		}
+		settings = append(settings, Setting{Key: key, Value: value, Source: "environment " + name})
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Source < settings[j].Source
+	})
+	return settings, nil
+}
+
+// envKey finds the dotted key an underscored variable name stands for.
+// Underscores separate keys and also occur within them, so every key that
+// starts the name is tried. Keys of maps holding settings per name, such as
+// service_overrides, cannot be set this way.
+func envKey(t reflect.Type, name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return envKey(t.Elem(), name)
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Struct {
+			return "", false
+		}
+		return name, true
+	case reflect.Struct:
+	default:
+		return "", false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		if name == key {
+			return key, true
+		}
+		if rest, ok := strings.CutPrefix(name, key+"_"); ok {
+			if nested, ok := envKey(field.Type, rest); ok {
+				return key + "." + nested, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Apply returns the configuration with the settings applied in order, with
+// the same merge rules as config files. It returns the configuration itself
+// when there are no settings.
+func (c *Config) Apply(settings []Setting) (*Config, error) {
+	cfg := c
+	for _, setting := range settings {
+		data, err := settingYAML(setting)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", setting.Source, err)
+		}
+		base := *cfg
+		applied, err := loadConfig(data, ".", &base, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", setting.Source, err)
+		}
+		applied.Sources = append(append([]string{}, applied.Sources...), setting.Source)
+		cfg = applied
+	}
+	return cfg, nil
+}
+
+// settingYAML writes a setting as a config document
+func settingYAML(setting Setting) ([]byte, error) {
+	var value interface{} = setting.Value
+	if setting.Value != "" {
+		var parsed interface{}
+		if err := yaml.Unmarshal([]byte(setting.Value), &parsed); err == nil {
+			value = parsed
+		}
+	}
+
+	parts := strings.Split(setting.Key, ".")
+	for i := len(parts) - 1; i >= 0; i-- {
+		if parts[i] == "" {
+			return nil, fmt.Errorf("invalid key %q", setting.Key)
+		}
+		value = yaml.MapSlice{{Key: parts[i], Value: value}}
+	}
+	return yaml.Marshal(value)
+}
@@ -0,0 +1,155 @@
+package fixer
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+)
+
+// lineBlock is the 1-based lines [start, end] one entry of a block mapping or
+// sequence is written on
+type lineBlock struct {
+	start, end int
+}
+
+// entryBlocks returns the lines of each entry of a block mapping, or of a
+// block sequence when dash is set. Comment lines directly above an entry move
+// with it, except above the first entry; blank lines and comments set apart
+// from the entries stay where they are. It reports false if an entry does not
+// start on a line of its own.
+func entryBlocks(editor *lineEditor, entries []ast.Node, dash bool) ([]lineBlock, bool) {
+	firsts := make([]int, len(entries))
+	lasts := make([]int, len(entries))
+	indent := -1
+	for i, entry := range entries {
+		first, last := nodeLines(entry)
+		if first == 0 || (i > 0 && first <= lasts[i-1]) {
+			return nil, false
+		}
+		text := editor.text(first)
+		spaces := leadingSpaces(text)
+		if spaces >= len(text) || (text[spaces] == '-') != dash || (indent >= 0 && spaces != indent) {
+			return nil, false
+		}
+		indent = spaces
+		firsts[i], lasts[i] = first, min(last, editor.count())
+	}
+
+	blocks := make([]lineBlock, len(entries))
+	for i := range entries {
+		end := lasts[i]
+		if i+1 < len(entries) {
+			// Lines up to the next entry belong to this one, except blank
+			// lines and comments no deeper than the entries
+			end = firsts[i+1] - 1
+			for end > lasts[i] && isEntrySeparator(editor.text(end), indent) {
+				end--
+			}
+		} else {
+			for end < editor.count() && isNestedComment(editor.text(end+1), indent) {
+				end++
+			}
+			for end > firsts[i] && isBlankLine(editor.text(end)) {
+				end--
+			}
+		}
+		blocks[i] = lineBlock{start: firsts[i], end: end}
+	}
+
+	for i := 1; i < len(blocks); i++ {
+		for blocks[i].start-1 > blocks[i-1].end && isCommentLine(editor.text(blocks[i].start-1)) {
+			blocks[i].start--
+		}
+	}
+	return blocks, true
+}
+
+// arrangeBlocks moves the blocks into the given order of their indices. The
+// lines between blocks keep their place.
+func arrangeBlocks(editor *lineEditor, blocks []lineBlock, order []int) {
+	lines := make([]int, 0)
+	for i, index := range order {
+		for line := blocks[index].start; line <= blocks[index].end; line++ {
+			lines = append(lines, line)
+		}
+		if i+1 < len(blocks) {
+			for line := blocks[i].end + 1; line < blocks[i+1].start; line++ {
+				lines = append(lines, line)
+			}
+		}
+	}
+	editor.moveLines(blocks[0].start, lines)
+}
+
+// nodeLines returns the first and last line holding a token of node, or
+// zeros if it has none
+func nodeLines(node ast.Node) (int, int) {
+	span := &lineSpan{}
+	ast.Walk(span, node)
+	return span.first, span.last
+}
+
+// lineSpan records the lines tokens are on while walking the AST
+type lineSpan struct {
+	first, last int
+}
+
+// Visit implements ast.Visitor
+func (s *lineSpan) Visit(node ast.Node) ast.Visitor {
+	switch n := node.(type) {
+	case *ast.CommentGroupNode, *ast.CommentNode:
+		return nil
+	case *ast.NullNode:
+		// Implicit nulls hold no text of their own
+		return s
+	case *ast.LiteralNode:
+		if n.Value != nil {
+			tk := n.Value.GetToken()
+			s.add(tk.Position.Line + strings.Count(tk.Origin, "\n") - 1)
+		}
+	case *ast.MappingNode:
+		if n.End != nil {
+			s.add(n.End.Position.Line)
+		}
+	case *ast.SequenceNode:
+		if n.End != nil {
+			s.add(n.End.Position.Line)
+		}
+	}
+	if tk := node.GetToken(); tk != nil && tk.Position != nil {
+		s.add(tk.Position.Line)
+	}
+	return s
+}
+
+// add extends the span to a line
+func (s *lineSpan) add(line int) {
+	if line <= 0 {
+		return
+	}
+	if s.first == 0 || line < s.first {
+		s.first = line
+	}
+	if line > s.last {
+		s.last = line
+	}
+}
+
+// isCommentLine checks if a line holds only a comment
+func isCommentLine(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	return len(trimmed) > 0 && trimmed[0] == '#'
+}
+
+// isEntrySeparator checks if a line is blank or a comment indented no deeper
+// than entries at indent
+func isEntrySeparator(line []byte, indent int) bool {
+	return isBlankLine(line) || (isCommentLine(line) && leadingSpaces(line) <= indent)
+}
+
+// isNestedComment checks if a line is a comment indented deeper than entries
+// at indent
+func isNestedComment(line []byte, indent int) bool {
+	return isCommentLine(line) && leadingSpaces(line) > indent
+}
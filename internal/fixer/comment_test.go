@@ -22,9 +22,7 @@ func getFixturesDir() string {
 	return "tests/fixtures"
 }
 
-// TestFix_WithComments tests that the fixing logic works on files with comments
-// NOTE: This test verifies that fixing logic (reordering, alphabetization) works correctly.
-// Comment preservation is a known limitation - see README.md for details.
+// TestFix_WithComments tests that fixing a file with comments keeps them
 func TestFix_WithComments(t *testing.T) {
 	fixturesDir := getFixturesDir()
 	inputFile := filepath.Join(fixturesDir, "with-comments-invalid.yml")
@@ -102,8 +100,13 @@ func TestFix_WithComments(t *testing.T) {
 		t.Fatalf("Fixed file should be valid YAML: %v", err)
 	}
 
-	t.Logf("Fixing succeeded with %d changes", len(changes))
-	t.Logf("Note: Comments may not be preserved with current implementation (see README)")
+	expected, err := os.ReadFile(filepath.Join(fixturesDir, "with-comments-expected.yml"))
+	if err != nil {
+		t.Fatalf("Failed to read expected file: %v", err)
+	}
+	if fixedStr != string(expected) {
+		t.Errorf("Fixed file does not match with-comments-expected.yml:\n%s", fixedStr)
+	}
 }
 
 // TestFix_MultiServiceInvalid tests fixing the multi-service invalid file
@@ -248,8 +251,72 @@ func TestFix_MixedEnvFormats(t *testing.T) {
 	t.Logf("Mixed env formats fix completed with %d changes", len(changes))
 }
 
-// TestFix_YamlAnchors tests fixing files with YAML anchors
-// NOTE: Anchors may not be preserved in output - this is a known limitation
+// TestFix_MixedEnvFormats_MapStyle tests the full output of converting list
+// environments to map form and sorting them
+func TestFix_MixedEnvFormats_MapStyle(t *testing.T) {
+	inputData, err := os.ReadFile(filepath.Join(getFixturesDir(), "mixed-env-formats.yml"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.Environment = "map"
+	fixedData, _, err := FixBytes(inputData, cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+
+	expected := `# Mixed environment variable formats
+# Tests different ways to define env vars
+
+version: '3.8'
+
+services:
+  # Service using list format with KEY=value
+  web-list:
+    container_name: web-list
+    image: nginx:latest
+    environment:
+      AAA_VAR: aaa_value
+      MMM_VAR: mmm_value
+      ZZZ_VAR: zzz_value
+    restart: always
+
+  # Service using list format with just KEY (no value)
+  web-keys:
+    container_name: web-keys
+    image: nginx:alpine
+    environment:
+      AAA_ENABLE:
+      MMM_ENABLE:
+      ZZZ_ENABLE:
+    restart: always
+
+  # Service using list format with variable substitution
+  web-vars:
+    container_name: web-vars
+    image: nginx:mainline
+    environment:
+      AAA_USER: ${AAA_USER}
+      MMM_HOST: ${MMM_HOST:-default}
+      ZZZ_PASSWORD: ${ZZZ_PASSWORD}
+    restart: always
+
+networks:
+  default:
+    driver: bridge
+`
+	if string(fixedData) != expected {
+		t.Errorf("Unexpected output:\n%s", fixedData)
+	}
+
+	// A second run finds nothing left to fix
+	if _, changes, err := FixBytes(fixedData, cfg); err != nil || len(changes) != 0 {
+		t.Errorf("Expected the fixed file to be stable, got %v (%v)", changes, err)
+	}
+}
+
+// TestFix_YamlAnchors tests that fixing files with YAML anchors keeps them
 func TestFix_YamlAnchors(t *testing.T) {
 	fixturesDir := getFixturesDir()
 	inputFile := filepath.Join(fixturesDir, "yaml-anchors.yml")
@@ -287,9 +354,10 @@ func TestFix_YamlAnchors(t *testing.T) {
 		}
 	}
 
-	// Note about anchors
-	if !strings.Contains(fixedStr, "&common-env") {
-		t.Log("Note: YAML anchors may be expanded during fix (expected behavior)")
+	for _, anchor := range []string{"&common-env", "<<: *common-env"} {
+		if !strings.Contains(fixedStr, anchor) {
+			t.Errorf("Expected '%s' to be kept:\n%s", anchor, fixedStr)
+		}
 	}
 
 	t.Logf("YAML anchors fix completed with %d changes", len(changes))
@@ -318,9 +386,9 @@ func TestFix_MultiDocument(t *testing.T) {
 
 	fixedStr := string(fixedData)
 
-	// Verify document separator is preserved (Go yaml should handle this)
+	// Verify document separator is preserved
 	if !strings.Contains(fixedStr, "---") {
-		t.Log("Note: Multi-document separator may not be preserved (Go YAML behavior)")
+		t.Error("Expected the document separator to be kept")
 	}
 
 	// Verify services from first document are still there
@@ -338,8 +406,7 @@ func TestFix_MultiDocument(t *testing.T) {
 	}
 }
 
-// TestFix_ExactPosition tests that inline comments are handled correctly
-// This test demonstrates the current limitation with comment preservation
+// TestFix_ExactPosition tests that inline comments move with their lines
 func TestFix_ExactPosition(t *testing.T) {
 	// Create a specific test case with inline comments
 	yaml := `services:
@@ -357,30 +424,15 @@ func TestFix_ExactPosition(t *testing.T) {
 		t.Fatalf("FixBytes failed: %v", err)
 	}
 
-	fixedStr := string(fixedData)
-
-	// After fix, container_name should come before image
-	// Fields should be alphabetized
-
-	containerIdx := strings.Index(fixedStr, "container_name:")
-	imageIdx := strings.Index(fixedStr, "image:")
-
-	if containerIdx == -1 || imageIdx == -1 {
-		t.Error("Both fields should exist")
-	}
-
-	if containerIdx > imageIdx {
-		t.Error("container_name should come before image after fixing")
-	}
-
-	// Verify env vars are alphabetized
-	zzzIdx := strings.Index(fixedStr, "ZZZ=value")
-	aaaIdx := strings.Index(fixedStr, "AAA=value")
-
-	if aaaIdx > zzzIdx {
-		t.Error("AAA should come before ZZZ")
+	expected := `services:
+  web:
+    container_name: web  # Inline comment on container_name
+    image: nginx:latest  # Inline comment on image
+    environment:
+      - AAA=value  # Comment on AAA
+      - ZZZ=value  # Comment on ZZZ
+`
+	if string(fixedData) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, fixedData)
 	}
-
-	t.Log("Core fixing logic works correctly")
-	t.Log("Note: Inline comments may not be preserved with current implementation")
 }
@@ -0,0 +1,66 @@
+package fixer
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// migrateDeprecated applies the safe deprecation fixes to raw YAML. It edits
+// the text line by line so comments and formatting elsewhere are preserved.
+func migrateDeprecated(data []byte, cfg *config.Config) ([]byte, []string, error) {
+	rules := cfg.Deprecations
+	if !rules.Enabled || !rules.Version {
+		return data, nil, nil
+	}
+
+	file, err := parser.ParseBytes("", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	editor := newLineEditor(data)
+	changes := make([]string, 0)
+
+	for _, doc := range file.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		var values []*ast.MappingValueNode
+		switch body := doc.Body.(type) {
+		case *ast.MappingNode:
+			values = body.Values
+		case *ast.MappingValueNode:
+			values = []*ast.MappingValueNode{body}
+		}
+
+		for _, value := range values {
+			if value.Key.String() != "version" {
+				continue
+			}
+			start := value.Key.GetToken().Position.Line
+			end := start
+			if token := value.Value.GetToken(); token != nil && token.Position.Line > end {
+				end = token.Position.Line
+			}
+			for line := start; line <= end; line++ {
+				editor.delete(line)
+			}
+
+			// Drop the blank line that separated version from the next key,
+			// unless that would join it to the previous content
+			if end < editor.count() && isBlankLine(editor.text(end+1)) && (start == 1 || isSeparatorLine(editor.text(start-1))) {
+				editor.delete(end + 1)
+			}
+			changes = append(changes, fmt.Sprintf("removed obsolete 'version' (line %d)", start))
+		}
+	}
+
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	return editor.bytes(), changes, nil
+}
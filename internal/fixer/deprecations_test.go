@@ -0,0 +1,80 @@
+package fixer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+)
+
+func TestMigrateDeprecated_RemovesVersion(t *testing.T) {
+	input := `# First document
+version: '3.8'
+
+services:
+  web:
+    image: nginx:latest  # keep this comment
+---
+name: other
+version: "3"
+networks:
+  frontend: {}
+`
+	expected := `# First document
+services:
+  web:
+    image: nginx:latest  # keep this comment
+---
+name: other
+networks:
+  frontend: {}
+`
+
+	cfg := config.NewDefaultConfig()
+	output, changes, err := migrateDeprecated([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("migrateDeprecated failed: %v", err)
+	}
+	if len(changes) != 0 || string(output) != input {
+		t.Errorf("Expected no changes while disabled, got %v", changes)
+	}
+
+	cfg.Deprecations.Enabled = true
+	output, changes, err = migrateDeprecated([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("migrateDeprecated failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("Expected 2 changes, got %v", changes)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestFixBytes_RemovesVersionWithoutReordering(t *testing.T) {
+	input := `version: '3.8'
+
+services:
+  web:
+    # Web server
+    image: nginx:latest
+    restart: always
+`
+
+	cfg := config.NewDefaultConfig()
+	cfg.Deprecations.Enabled = true
+	output, changes, err := FixBytes([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Errorf("Expected 1 change, got %v", changes)
+	}
+	if strings.Contains(string(output), "version") {
+		t.Error("version should have been removed")
+	}
+	if !strings.Contains(string(output), "# Web server") {
+		t.Error("Comments should be preserved")
+	}
+}
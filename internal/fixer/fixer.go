@@ -6,7 +6,8 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
 	"github.com/yourusername/compose-validator/internal/config"
 	"github.com/yourusername/compose-validator/internal/parser"
 )
@@ -19,6 +20,20 @@ type FixResult struct {
 	Error   error
 }
 
+// fixPass rewrites YAML and reports what it changed
+type fixPass func(data []byte, cfg *config.Config) ([]byte, []string, error)
+
+// fixPasses are applied in order. Every pass edits lines in place; quoting
+// runs once entries have their final form and place, and layout passes last.
+var fixPasses = []fixPass{
+	migrateDeprecated,
+	normalizeKeyValueStyles,
+	reorderServices,
+	normalizeQuoting,
+	separateFieldGroups,
+	normalizeFormat,
+}
+
 // Fix repairs violations in a Docker Compose file
 func Fix(file *parser.ComposeFile, cfg *config.Config) (*FixResult, error) {
 	result := &FixResult{
@@ -32,42 +47,14 @@ func Fix(file *parser.ComposeFile, cfg *config.Config) (*FixResult, error) {
 		return nil, fmt.Errorf("failed to read file %s: %w", file.Path, err)
 	}
 
-	// Parse into generic structure
-	var composeContent map[string]interface{}
-	if err := yaml.Unmarshal(data, &composeContent); err != nil {
-		return nil, fmt.Errorf("failed to parse file %s: %w", file.Path, err)
-	}
-
-	// Get services
-	services, ok := composeContent["services"].(map[string]interface{})
-	if !ok {
-		// No services to fix
-		result.Fixed = false
-		return result, nil
-	}
-
-	fixed := false
-	for serviceName, svc := range services {
-		if svcMap, ok := svc.(map[string]interface{}); ok {
-			fieldOrder := cfg.GetFieldOrder(serviceName)
-			svcFixed, svcChanges := fixService(serviceName, svcMap, fieldOrder, cfg)
-			if svcFixed {
-				fixed = true
-				result.Changes = append(result.Changes, svcChanges...)
-				services[serviceName] = svcMap
-			}
-		}
+	output, changes, err := FixBytes(data, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fix file %s: %w", file.Path, err)
 	}
 
-	if fixed {
+	if len(changes) > 0 {
 		result.Fixed = true
-
-		// Marshal back to YAML with comment preservation
-		// For now, we'll use standard marshaling
-		output, err := yaml.Marshal(composeContent)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal fixed content: %w", err)
-		}
+		result.Changes = append(result.Changes, changes...)
 
 		// Write back to file
 		if err := os.WriteFile(file.Path, output, 0644); err != nil {
@@ -80,293 +67,297 @@ func Fix(file *parser.ComposeFile, cfg *config.Config) (*FixResult, error) {
 
 // FixBytes fixes violations in YAML bytes
 func FixBytes(data []byte, cfg *config.Config) ([]byte, []string, error) {
-	// Parse into generic structure
-	var composeContent map[string]interface{}
-	if err := yaml.Unmarshal(data, &composeContent); err != nil {
+	changes := make([]string, 0)
+	for _, pass := range fixPasses {
+		var passChanges []string
+		var err error
+		data, passChanges, err = pass(data, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, passChanges...)
+	}
+
+	return data, nilIfEmpty(changes), nil
+}
+
+// reorderServices reorders and alphabetizes service fields. Whole lines are
+// moved, so comments, quoting and the rest of the document stay as written.
+func reorderServices(data []byte, cfg *config.Config) ([]byte, []string, error) {
+	file, err := parser.ParseBytes("", data)
+	if err != nil {
 		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
-	// Get services
-	services, ok := composeContent["services"].(map[string]interface{})
-	if !ok {
-		// No services to fix
-		return data, nil, nil
+	services := make([]parser.Service, 0)
+	for _, service := range file.GetServices() {
+		services = append(services, service)
 	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Line < services[j].Line
+	})
 
+	editor := newLineEditor(data)
 	changes := make([]string, 0)
-	fixed := false
-
-	for serviceName, svc := range services {
-		if svcMap, ok := svc.(map[string]interface{}); ok {
-			fieldOrder := cfg.GetFieldOrder(serviceName)
-			svcFixed, svcChanges := fixService(serviceName, svcMap, fieldOrder, cfg)
-			if svcFixed {
-				fixed = true
-				changes = append(changes, svcChanges...)
-				services[serviceName] = svcMap
-			}
-		}
+	for _, service := range services {
+		changes = append(changes, fixService(editor, service, cfg.ForService(service.Ref()))...)
 	}
 
-	if !fixed {
+	if len(changes) == 0 {
 		return data, nil, nil
 	}
+	return editor.bytes(), changes, nil
+}
 
-	// Marshal back to YAML
-	output, err := yaml.Marshal(composeContent)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to marshal fixed content: %w", err)
+// nilIfEmpty returns nil for an empty change list, as reported when nothing
+// was fixed
+func nilIfEmpty(changes []string) []string {
+	if len(changes) == 0 {
+		return nil
 	}
-
-	return output, changes, nil
+	return changes
 }
 
-// fixService repairs a single service configuration
-func fixService(name string, svc map[string]interface{}, fieldOrder []string, cfg *config.Config) (bool, []string) {
+// fixService repairs a single service. The entries of each field are sorted
+// before the fields are moved into the configured order, with fields that
+// have no position in the field order at the end.
+func fixService(editor *lineEditor, service parser.Service, cfg *config.Config) []string {
+	if service.Node == nil || service.Node.IsFlowStyle {
+		return nil
+	}
 	changes := make([]string, 0)
-	fixed := false
-
-	// Create ordered map
-	ordered := make(map[string]interface{}, len(svc))
-
-	// Add fields in correct order
-	for _, field := range fieldOrder {
-		if value, ok := svc[field]; ok {
-			// Alphabetize if needed
-			alphabetizedValue, alphaFixed := alphabetizeField(field, value, cfg)
-			if alphaFixed {
-				changes = append(changes, fmt.Sprintf("service '%s': alphabetized '%s'", name, field))
-				fixed = true
-			}
 
-			ordered[field] = alphabetizedValue
-			delete(svc, field)
+	for _, value := range service.Node.Values {
+		field := value.Key.String()
+		if alphabetizeField(editor, service, field, value.Value, cfg) {
+			changes = append(changes, fmt.Sprintf("service '%s': alphabetized '%s'", service.Name, field))
 		}
 	}
 
-	// Add any remaining fields (not in field order) at the end
-	for field, value := range svc {
-		alphabetizedValue, alphaFixed := alphabetizeField(field, value, cfg)
-		if alphaFixed {
-			changes = append(changes, fmt.Sprintf("service '%s': alphabetized '%s'", name, field))
-			fixed = true
-		}
-		ordered[field] = alphabetizedValue
+	if isFieldOrderCorrect(service.FieldOrder, cfg.FieldOrder, cfg) {
+		return changes
 	}
-
-	// Check if field order was wrong
-	if !isFieldOrderCorrect(svc, fieldOrder) {
-		changes = append(changes, fmt.Sprintf("service '%s': reordered fields", name))
-		fixed = true
+	entries := make([]ast.Node, 0, len(service.Node.Values))
+	for _, value := range service.Node.Values {
+		entries = append(entries, value)
 	}
-
-	// Copy ordered back to svc
-	for field, value := range ordered {
-		svc[field] = value
+	blocks, ok := entryBlocks(editor, entries, false)
+	if !ok {
+		return changes
 	}
-
-	return fixed, changes
+	arrangeBlocks(editor, blocks, fieldPermutation(service.FieldOrder, cfg.FieldOrder, cfg))
+	return append(changes, fmt.Sprintf("service '%s': reordered fields", service.Name))
 }
 
-// isFieldOrderCorrect checks if fields are in the expected order
-func isFieldOrderCorrect(svc map[string]interface{}, fieldOrder []string) bool {
-	lastIndex := -1
-	for field := range svc {
-		idx := getFieldIndex(field, fieldOrder)
-		if idx >= 0 {
-			if idx < lastIndex {
-				return false
+// fieldPermutation returns the indices of fields, given in file order, in the
+// configured order
+func fieldPermutation(fields []string, fieldOrder []string, cfg *config.Config) []int {
+	keys := cfg.OrderFields(fieldOrder, fields)
+	keys = append(keys, unplacedFields(fields, fieldOrder, cfg)...)
+
+	used := make([]bool, len(fields))
+	order := make([]int, 0, len(fields))
+	for _, key := range keys {
+		for i, field := range fields {
+			if !used[i] && field == key {
+				used[i] = true
+				order = append(order, i)
+				break
 			}
-			lastIndex = idx
 		}
 	}
-	return true
+	return order
 }
 
-// getFieldIndex returns the index of a field in the field order
-func getFieldIndex(field string, fieldOrder []string) int {
-	for i, f := range fieldOrder {
-		if f == field {
-			return i
+// unplacedFields returns the fields without a position in the field order,
+// sorted by name when unlisted_order is alphabetical
+func unplacedFields(fields []string, fieldOrder []string, cfg *config.Config) []string {
+	unplaced := make([]string, 0)
+	for _, field := range fields {
+		if config.FieldPosition(fieldOrder, field) < 0 {
+			unplaced = append(unplaced, field)
 		}
 	}
-	return -1
+	if cfg.UnlistedOrder == "alphabetical" {
+		sort.Strings(unplaced)
+	}
+	return unplaced
 }
 
-// alphabetizeField alphabetizes a field's value if needed
-func alphabetizeField(field string, value interface{}, cfg *config.Config) (interface{}, bool) {
+// isFieldOrderCorrect checks if fields, given in file order, are in the
+// expected order
+func isFieldOrderCorrect(fields []string, fieldOrder []string, cfg *config.Config) bool {
+	placed := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if config.FieldPosition(fieldOrder, field) >= 0 {
+			placed = append(placed, field)
+		}
+	}
+	expected := cfg.OrderFields(fieldOrder, placed)
+	for i := range placed {
+		if placed[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// alphabetizeField sorts the entries of a field by moving their lines. Flow
+// collections written on one line are sorted in place.
+func alphabetizeField(editor *lineEditor, service parser.Service, field string, node ast.Node, cfg *config.Config) bool {
 	if !cfg.ShouldAlphabetize(field) {
-		return value, false
+		return false
 	}
+	keys := entryKeys(service, field)
+	order, changed := sortedOrder(keys, cfg.Comparison(field))
+	if !changed {
+		return false
+	}
+
+	var entries []ast.Node
+	dash := false
+	switch n := parser.UnwrapNode(node).(type) {
+	case *ast.SequenceNode:
+		if n.IsFlowStyle {
+			return sortFlowEntries(editor, n, order)
+		}
+		entries, dash = n.Values, true
+	case *ast.MappingNode:
+		if n.IsFlowStyle {
+			return sortFlowEntries(editor, n, order)
+		}
+		for _, value := range n.Values {
+			entries = append(entries, value)
+		}
+	}
+	if len(entries) != len(keys) {
+		return false
+	}
+
+	blocks, ok := entryBlocks(editor, entries, dash)
+	if !ok {
+		return false
+	}
+	arrangeBlocks(editor, blocks, order)
+	return true
+}
 
+// entryKeys returns the keys the entries of a field are sorted by, in file
+// order
+func entryKeys(service parser.Service, field string) []string {
+	extract := extractEnvKey
 	switch field {
 	case "environment":
-		return alphabetizeEnvironment(value)
 	case "volumes":
-		return alphabetizeVolumes(value)
+		extract = extractVolumeKey
 	case "labels":
-		return alphabetizeLabels(value)
+		extract = extractLabelKey
+	default:
+		return nil
 	}
 
-	return value, false
-}
-
-// alphabetizeEnvironment alphabetizes environment variables
-func alphabetizeEnvironment(value interface{}) (interface{}, bool) {
-	switch v := value.(type) {
+	switch v := service.Config[field].(type) {
 	case []interface{}:
-		if len(v) < 2 {
-			return value, false
-		}
-
-		sorted := make([]interface{}, len(v))
-		copy(sorted, v)
-
-		sort.Slice(sorted, func(i, j int) bool {
-			keyI := extractEnvKey(sorted[i])
-			keyJ := extractEnvKey(sorted[j])
-			return strings.ToLower(keyI) < strings.ToLower(keyJ)
-		})
-
-		// Check if sorting changed anything
-		for i := range v {
-			if v[i] != sorted[i] {
-				return sorted, true
-			}
-		}
-		return value, false
-
-	case map[string]interface{}:
-		if len(v) < 2 {
-			return value, false
-		}
-
 		keys := make([]string, 0, len(v))
-		for k := range v {
-			keys = append(keys, k)
+		for _, item := range v {
+			keys = append(keys, extract(item))
 		}
-
-		sortedKeys := make([]string, len(keys))
-		copy(sortedKeys, keys)
-		sort.Slice(sortedKeys, func(i, j int) bool {
-			return strings.ToLower(sortedKeys[i]) < strings.ToLower(sortedKeys[j])
-		})
-
-		// Check if keys were already sorted
-		sorted := true
-		for i, key := range keys {
-			if key != sortedKeys[i] {
-				sorted = false
-				break
-			}
-		}
-
-		if sorted {
-			return value, false
-		}
-
-		// Create new ordered map
-		ordered := make(map[string]interface{}, len(v))
-		for _, key := range sortedKeys {
-			ordered[key] = v[key]
+		return keys
+	case map[string]interface{}:
+		if field == "volumes" {
+			return nil
 		}
-		return ordered, true
+		// Decoded mappings do not keep key order
+		return service.Keys(field)
 	}
-
-	return value, false
+	return nil
 }
 
-// alphabetizeVolumes alphabetizes volumes by source path
-func alphabetizeVolumes(value interface{}) (interface{}, bool) {
-	switch v := value.(type) {
-	case []interface{}:
-		if len(v) < 2 {
-			return value, false
-		}
-
-		sorted := make([]interface{}, len(v))
-		copy(sorted, v)
-
-		sort.Slice(sorted, func(i, j int) bool {
-			sourceI := extractVolumeKey(sorted[i])
-			sourceJ := extractVolumeKey(sorted[j])
-			return strings.ToLower(sourceI) < strings.ToLower(sourceJ)
-		})
+// sortedOrder returns the indices of keys in sorted order, keeping equal keys
+// in place, and whether that differs from the given order
+func sortedOrder(keys []string, mode string) ([]int, bool) {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return config.CompareKeys(mode, keys[order[i]], keys[order[j]]) < 0
+	})
 
-		// Check if sorting changed anything
-		for i := range v {
-			if v[i] != sorted[i] {
-				return sorted, true
-			}
+	for i, index := range order {
+		if index != i {
+			return order, true
 		}
-		return value, false
 	}
-
-	return value, false
+	return order, false
 }
 
-// alphabetizeLabels alphabetizes labels by key
-func alphabetizeLabels(value interface{}) (interface{}, bool) {
-	switch v := value.(type) {
-	case []interface{}:
-		if len(v) < 2 {
-			return value, false
+// sortFlowEntries reorders the entries of a flow collection written on one
+// line by swapping their text. Collections with nested or multi-line entries
+// are left as they are.
+func sortFlowEntries(editor *lineEditor, node ast.Node, order []int) bool {
+	var start, end *token.Token
+	var entries [][2]ast.Node
+	switch n := node.(type) {
+	case *ast.SequenceNode:
+		start, end = n.Start, n.End
+		for _, value := range n.Values {
+			entries = append(entries, [2]ast.Node{value, value})
 		}
-
-		sorted := make([]interface{}, len(v))
-		copy(sorted, v)
-
-		sort.Slice(sorted, func(i, j int) bool {
-			keyI := extractLabelKey(sorted[i])
-			keyJ := extractLabelKey(sorted[j])
-			return strings.ToLower(keyI) < strings.ToLower(keyJ)
-		})
-
-		// Check if sorting changed anything
-		for i := range v {
-			if v[i] != sorted[i] {
-				return sorted, true
-			}
+	case *ast.MappingNode:
+		start, end = n.Start, n.End
+		for _, value := range n.Values {
+			entries = append(entries, [2]ast.Node{value.Key, value.Value})
 		}
-		return value, false
+	}
+	if start == nil || end == nil || start.Position.Line != end.Position.Line || len(entries) != len(order) {
+		return false
+	}
 
-	case map[string]interface{}:
-		// Similar to environment map handling
-		if len(v) < 2 {
-			return value, false
+	line := start.Position.Line
+	text := editor.text(line)
+	spans := make([]span, 0, len(entries))
+	for _, entry := range entries {
+		first, last := entry[0].GetToken(), entry[1].GetToken()
+		if _, ok := entry[1].(ast.ScalarNode); !ok || first == nil || last == nil ||
+			first.Position.Line != line || last.Position.Line != line {
+			return false
 		}
-
-		keys := make([]string, 0, len(v))
-		for k := range v {
-			keys = append(keys, k)
+		from := first.Position.Column - 1
+		to := flowScalarEnd(text, last.Position.Column-1)
+		if from < 0 || to < from {
+			return false
 		}
+		spans = append(spans, span{start: from, end: to})
+	}
 
-		sortedKeys := make([]string, len(keys))
-		copy(sortedKeys, keys)
-		sort.Slice(sortedKeys, func(i, j int) bool {
-			return strings.ToLower(sortedKeys[i]) < strings.ToLower(sortedKeys[j])
-		})
-
-		sorted := true
-		for i, key := range keys {
-			if key != sortedKeys[i] {
-				sorted = false
-				break
-			}
-		}
+	for i, index := range order {
+		editor.replaceSpan(line, spans[i].start, spans[i].end, string(text[spans[index].start:spans[index].end]))
+	}
+	return true
+}
 
-		if sorted {
-			return value, false
-		}
+// flowScalarEnd returns the offset just past the scalar starting at start in
+// a flow collection, or -1 if a quoted scalar is not closed on this line
+func flowScalarEnd(text []byte, start int) int {
+	if start >= len(text) {
+		return -1
+	}
+	if text[start] == '"' || text[start] == '\'' {
+		return scalarEnd(text, start)
+	}
 
-		ordered := make(map[string]interface{}, len(v))
-		for _, key := range sortedKeys {
-			ordered[key] = v[key]
+	end := start
+	for end < len(text) && !strings.ContainsRune(",]}", rune(text[end])) {
+		if text[end] == '#' && end > start && text[end-1] == ' ' {
+			break
 		}
-		return ordered, true
+		end++
 	}
-
-	return value, false
+	for end > start && (text[end-1] == ' ' || text[end-1] == '\t') {
+		end--
+	}
+	return end
 }
 
 // extractEnvKey extracts the key from an environment variable entry
@@ -1,6 +1,7 @@
 package fixer
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/yourusername/compose-validator/internal/config"
@@ -9,198 +10,106 @@ import (
 func TestAlphabetizeEnvironment_List(t *testing.T) {
 	tests := []struct {
 		name     string
-		input    interface{}
-		expected interface{}
+		input    []string
+		expected []string
 		changed  bool
 	}{
 		{
-			name: "already alphabetized",
-			input: []interface{}{
-				"AAA=value",
-				"BBB=value",
-				"CCC=value",
-			},
-			expected: []interface{}{
-				"AAA=value",
-				"BBB=value",
-				"CCC=value",
-			},
-			changed: false,
+			name:     "already alphabetized",
+			input:    []string{"AAA=value", "BBB=value", "CCC=value"},
+			expected: []string{"AAA=value", "BBB=value", "CCC=value"},
+			changed:  false,
 		},
 		{
-			name: "needs sorting",
-			input: []interface{}{
-				"ZZZ=value",
-				"AAA=value",
-				"MMM=value",
-			},
-			expected: []interface{}{
-				"AAA=value",
-				"MMM=value",
-				"ZZZ=value",
-			},
-			changed: true,
+			name:     "needs sorting",
+			input:    []string{"ZZZ=value", "AAA=value", "MMM=value"},
+			expected: []string{"AAA=value", "MMM=value", "ZZZ=value"},
+			changed:  true,
 		},
 		{
-			name: "case insensitive sorting",
-			input: []interface{}{
-				"zzz=value",
-				"AAA=value",
-				"BBB=value",
-			},
-			expected: []interface{}{
-				"AAA=value",
-				"BBB=value",
-				"zzz=value",
-			},
-			changed: true,
+			name:     "case insensitive sorting",
+			input:    []string{"zzz=value", "AAA=value", "BBB=value"},
+			expected: []string{"AAA=value", "BBB=value", "zzz=value"},
+			changed:  true,
 		},
 		{
 			name:     "empty list",
-			input:    []interface{}{},
-			expected: []interface{}{},
+			input:    []string{},
+			expected: []string{},
 			changed:  false,
 		},
 		{
 			name:     "single item",
-			input:    []interface{}{"KEY=value"},
-			expected: []interface{}{"KEY=value"},
+			input:    []string{"KEY=value"},
+			expected: []string{"KEY=value"},
 			changed:  false,
 		},
 		{
-			name: "with env var format",
-			input: []interface{}{
-				"${VAR3}=value3",
-				"${VAR1}=value1",
-				"${VAR2}=value2",
-			},
-			expected: []interface{}{
-				"${VAR1}=value1",
-				"${VAR2}=value2",
-				"${VAR3}=value3",
-			},
-			changed: true,
+			name:     "with env var format",
+			input:    []string{"${VAR3}=value3", "${VAR1}=value1", "${VAR2}=value2"},
+			expected: []string{"${VAR1}=value1", "${VAR2}=value2", "${VAR3}=value3"},
+			changed:  true,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, changed := alphabetizeEnvironment(test.input)
-
-			if changed != test.changed {
-				t.Errorf("Expected changed=%v, got %v", test.changed, changed)
-			}
-
-			if changed {
-				resultSlice, ok := result.([]interface{})
-				if !ok {
-					t.Fatalf("Expected []interface{}, got %T", result)
-				}
-
-				expectedSlice, ok := test.expected.([]interface{})
-				if !ok {
-					t.Fatalf("Expected []interface{} in test data, got %T", test.expected)
-				}
-
-				if len(resultSlice) != len(expectedSlice) {
-					t.Errorf("Expected %d items, got %d", len(expectedSlice), len(resultSlice))
-				}
-
-				for i := range expectedSlice {
-					if resultSlice[i] != expectedSlice[i] {
-						t.Errorf("Item %d: expected '%v', got '%v'", i, expectedSlice[i], resultSlice[i])
-					}
-				}
-			}
+			checkAlphabetized(t, config.NewDefaultConfig(), "environment", test.input, test.expected, test.changed)
 		})
 	}
 }
 
 func TestAlphabetizeEnvironment_Map(t *testing.T) {
-	input := map[string]interface{}{
-		"ZZZ": "value3",
-		"AAA": "value1",
-		"MMM": "value2",
-	}
+	// Mapping entries are sorted by key in file order
+	input := "services:\n  web:\n    image: nginx\n    environment:\n      ZZZ: value3\n      AAA: value1\n      MMM: value2\n"
+	expected := "services:\n  web:\n    image: nginx\n    environment:\n      AAA: value1\n      MMM: value2\n      ZZZ: value3\n"
 
-	result, changed := alphabetizeEnvironment(input)
-
-	if !changed {
-		t.Error("Expected changed=true for unsorted map")
+	cfg := config.NewDefaultConfig()
+	output, changes, err := FixBytes([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
 	}
-
-	resultMap, ok := result.(map[string]interface{})
-	if !ok {
-		t.Fatalf("Expected map[string]interface{}, got %T", result)
+	if len(changes) == 0 {
+		t.Error("Expected changes for an unsorted map")
+	}
+	if string(output) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
 	}
 
-	// Check that keys are in order (Go 1.12+ preserves map iteration order)
-	expectedOrder := []string{"AAA", "MMM", "ZZZ"}
-	i := 0
-	for key := range resultMap {
-		if i >= len(expectedOrder) {
-			t.Error("More keys than expected")
-			break
-		}
-		if key != expectedOrder[i] {
-			t.Errorf("Key %d: expected '%s', got '%s'", i, expectedOrder[i], key)
-		}
-		i++
+	// Sorted mappings are left as they are
+	if _, changes, _ := FixBytes(output, cfg); len(changes) != 0 {
+		t.Errorf("Expected no changes for a sorted map, got %v", changes)
 	}
 }
 
 func TestAlphabetizeVolumes(t *testing.T) {
 	tests := []struct {
 		name     string
-		input    interface{}
+		input    []string
 		expected []string
 		changed  bool
 	}{
 		{
-			name: "already alphabetized",
-			input: []interface{}{
-				"/aaa:/container/aaa",
-				"/bbb:/container/bbb",
-				"/ccc:/container/ccc",
-			},
-			expected: []string{
-				"/aaa:/container/aaa",
-				"/bbb:/container/bbb",
-				"/ccc:/container/ccc",
-			},
-			changed: false,
+			name:     "already alphabetized",
+			input:    []string{"/aaa:/container/aaa", "/bbb:/container/bbb", "/ccc:/container/ccc"},
+			expected: []string{"/aaa:/container/aaa", "/bbb:/container/bbb", "/ccc:/container/ccc"},
+			changed:  false,
 		},
 		{
-			name: "needs sorting by source",
-			input: []interface{}{
-				"/zzz:/container/zzz",
-				"/aaa:/container/aaa",
-				"/mmm:/container/mmm",
-			},
-			expected: []string{
-				"/aaa:/container/aaa",
-				"/mmm:/container/mmm",
-				"/zzz:/container/zzz",
-			},
-			changed: true,
+			name:     "needs sorting by source",
+			input:    []string{"/zzz:/container/zzz", "/aaa:/container/aaa", "/mmm:/container/mmm"},
+			expected: []string{"/aaa:/container/aaa", "/mmm:/container/mmm", "/zzz:/container/zzz"},
+			changed:  true,
 		},
 		{
-			name: "complex paths",
-			input: []interface{}{
-				"/var/log:/container/log",
-				"/etc/config:/container/config",
-				"/home/data:/container/data",
-			},
-			expected: []string{
-				"/etc/config:/container/config",
-				"/home/data:/container/data",
-				"/var/log:/container/log",
-			},
-			changed: true,
+			name:     "complex paths",
+			input:    []string{"/var/log:/container/log", "/etc/config:/container/config", "/home/data:/container/data"},
+			expected: []string{"/etc/config:/container/config", "/home/data:/container/data", "/var/log:/container/log"},
+			changed:  true,
 		},
 		{
 			name:     "empty volumes",
-			input:    []interface{}{},
+			input:    []string{},
 			expected: []string{},
 			changed:  false,
 		},
@@ -208,28 +117,7 @@ func TestAlphabetizeVolumes(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, changed := alphabetizeVolumes(test.input)
-
-			if changed != test.changed {
-				t.Errorf("Expected changed=%v, got %v", test.changed, changed)
-			}
-
-			if changed {
-				resultSlice, ok := result.([]interface{})
-				if !ok {
-					t.Fatalf("Expected []interface{}, got %T", result)
-				}
-
-				if len(resultSlice) != len(test.expected) {
-					t.Errorf("Expected %d items, got %d", len(test.expected), len(resultSlice))
-				}
-
-				for i, expected := range test.expected {
-					if resultSlice[i] != expected {
-						t.Errorf("Item %d: expected '%v', got '%v'", i, expected, resultSlice[i])
-					}
-				}
-			}
+			checkAlphabetized(t, config.NewDefaultConfig(), "volumes", test.input, test.expected, test.changed)
 		})
 	}
 }
@@ -237,13 +125,13 @@ func TestAlphabetizeVolumes(t *testing.T) {
 func TestAlphabetizeLabels(t *testing.T) {
 	tests := []struct {
 		name     string
-		input    interface{}
+		input    []string
 		expected []string
 		changed  bool
 	}{
 		{
 			name: "already alphabetized",
-			input: []interface{}{
+			input: []string{
 				"traefik.enable=true",
 				"traefik.http.routers.app.rule=Host(`example.com`)",
 				"wud.watch=true",
@@ -256,59 +144,22 @@ func TestAlphabetizeLabels(t *testing.T) {
 			changed: false,
 		},
 		{
-			name: "needs sorting",
-			input: []interface{}{
-				"wud.watch=true",
-				"traefik.enable=true",
-				"com.example.label=value",
-			},
-			expected: []string{
-				"com.example.label=value",
-				"traefik.enable=true",
-				"wud.watch=true",
-			},
-			changed: true,
+			name:     "needs sorting",
+			input:    []string{"wud.watch=true", "traefik.enable=true", "com.example.label=value"},
+			expected: []string{"com.example.label=value", "traefik.enable=true", "wud.watch=true"},
+			changed:  true,
 		},
 		{
-			name: "without values",
-			input: []interface{}{
-				"zzz.label",
-				"aaa.label",
-				"mmm.label",
-			},
-			expected: []string{
-				"aaa.label",
-				"mmm.label",
-				"zzz.label",
-			},
-			changed: true,
+			name:     "without values",
+			input:    []string{"zzz.label", "aaa.label", "mmm.label"},
+			expected: []string{"aaa.label", "mmm.label", "zzz.label"},
+			changed:  true,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result, changed := alphabetizeLabels(test.input)
-
-			if changed != test.changed {
-				t.Errorf("Expected changed=%v, got %v", test.changed, changed)
-			}
-
-			if changed {
-				resultSlice, ok := result.([]interface{})
-				if !ok {
-					t.Fatalf("Expected []interface{}, got %T", result)
-				}
-
-				if len(resultSlice) != len(test.expected) {
-					t.Errorf("Expected %d items, got %d", len(test.expected), len(resultSlice))
-				}
-
-				for i, expected := range test.expected {
-					if resultSlice[i] != expected {
-						t.Errorf("Item %d: expected '%v', got '%v'", i, expected, resultSlice[i])
-					}
-				}
-			}
+			checkAlphabetized(t, config.NewDefaultConfig(), "labels", test.input, test.expected, test.changed)
 		})
 	}
 }
@@ -378,43 +229,228 @@ func TestIsFieldOrderCorrect(t *testing.T) {
 
 	tests := []struct {
 		name     string
-		svc      map[string]interface{}
+		fields   []string
 		expected bool
 	}{
 		{
-			name: "correct order",
-			svc: map[string]interface{}{
-				"container_name": "app",
-				"image":          "nginx",
-				"environment":    []interface{}{},
-			},
+			name:     "correct order",
+			fields:   []string{"container_name", "image", "environment"},
 			expected: true,
 		},
 		{
-			name: "wrong order - image before container_name",
-			svc: map[string]interface{}{
-				"image":          "nginx",
-				"container_name": "app",
-				"environment":    []interface{}{},
-			},
+			name:     "wrong order - image before container_name",
+			fields:   []string{"image", "container_name", "environment"},
 			expected: false,
 		},
 		{
-			name: "missing fields",
-			svc: map[string]interface{}{
-				"container_name": "app",
-				"image":          "nginx",
-			},
+			name:     "missing fields",
+			fields:   []string{"container_name", "image"},
 			expected: true, // Still correct since we only check present fields
 		},
+		{
+			name:     "unlisted fields are ignored",
+			fields:   []string{"container_name", "depends_on", "image"},
+			expected: true,
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := isFieldOrderCorrect(test.svc, cfg.FieldOrder)
+			result := isFieldOrderCorrect(test.fields, cfg.FieldOrder, cfg)
 			if result != test.expected {
 				t.Errorf("isFieldOrderCorrect() = %v, expected %v", result, test.expected)
 			}
 		})
 	}
 }
+
+func TestFixBytes_FieldOrderWildcards(t *testing.T) {
+	input := `services:
+  web:
+    x-note: hi
+    restart: always
+    image: nginx
+    depends_on:
+      - db
+    command: run
+`
+	cfg := config.NewDefaultConfig()
+	cfg.FieldOrder = []string{"image", "*", "x-*"}
+	cfg.UnlistedOrder = "alphabetical"
+
+	output, changes, err := FixBytes([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+	if len(changes) == 0 {
+		t.Fatal("Expected changes to be reported")
+	}
+
+	expected := []string{"image:", "command:", "depends_on:", "restart:", "x-note:"}
+	last := -1
+	for _, key := range expected {
+		idx := strings.Index(string(output), key)
+		if idx < last {
+			t.Fatalf("Expected fields in order %v, got:\n%s", expected, output)
+		}
+		last = idx
+	}
+
+	// The fixed output is left unchanged
+	_, changes, err = FixBytes(output, cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes on fixed output, got %v", changes)
+	}
+}
+
+func TestFixBytes_UnlistedOrder(t *testing.T) {
+	input := `services:
+  web:
+    restart: always
+    image: nginx
+    depends_on:
+      - db
+    container_name: web
+`
+	cfg := config.NewDefaultConfig()
+	cfg.FieldOrder = []string{"container_name", "image"}
+	cfg.UnlistedOrder = "alphabetical"
+
+	output, _, err := FixBytes([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+
+	// Fields without a position follow the placed ones, sorted by name
+	expected := []string{"container_name:", "image:", "depends_on:", "restart:"}
+	last := -1
+	for _, key := range expected {
+		idx := strings.Index(string(output), key)
+		if idx < last {
+			t.Fatalf("Expected fields in order %v, got:\n%s", expected, output)
+		}
+		last = idx
+	}
+}
+
+func TestFixBytes_PatternOverrides(t *testing.T) {
+	input := `services:
+  db-main:
+    image: postgres
+    environment:
+      - B=2
+      - A=1
+    container_name: db
+`
+	disabled := false
+	cfg := config.NewDefaultConfig()
+	cfg.ServiceOverrides = map[string]config.ServiceOverride{
+		"db-*": {
+			FieldOrder:      config.FieldOrder{"image", "environment", "container_name"},
+			Alphabetization: config.AlphabetizationOverride{Environment: &disabled},
+		},
+	}
+
+	output, changes, err := FixBytes([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes for a service matching its override, got %v:\n%s", changes, output)
+	}
+}
+
+func TestAlphabetizeEnvironment_Comparison(t *testing.T) {
+	input := []string{"APP_10=a", "api=b", "APP_2=c", "API=d"}
+
+	tests := []struct {
+		mode     string
+		expected []string
+	}{
+		// Keys differing only in case keep their order
+		{config.CompareCaseInsensitive, []string{"api=b", "API=d", "APP_10=a", "APP_2=c"}},
+		{config.CompareCaseSensitive, []string{"API=d", "api=b", "APP_10=a", "APP_2=c"}},
+		{config.CompareNatural, []string{"api=b", "API=d", "APP_2=c", "APP_10=a"}},
+		{config.CompareByte, []string{"API=d", "APP_10=a", "APP_2=c", "api=b"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.mode, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Alphabetization.Comparison.Environment = test.mode
+			checkAlphabetized(t, cfg, "environment", input, test.expected, true)
+		})
+	}
+}
+
+// checkAlphabetized fixes a service with the given list entries and checks
+// that they come out in the expected order
+func checkAlphabetized(t *testing.T, cfg *config.Config, field string, input, expected []string, changed bool) {
+	t.Helper()
+
+	compose := func(entries []string) string {
+		if len(entries) == 0 {
+			return "services:\n  web:\n    image: nginx\n    " + field + ": []\n"
+		}
+		return "services:\n  web:\n    image: nginx\n    " + field + ":\n      - " + strings.Join(entries, "\n      - ") + "\n"
+	}
+
+	output, changes, err := FixBytes([]byte(compose(input)), cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+	if (len(changes) > 0) != changed {
+		t.Errorf("Expected changed=%v, got %v", changed, changes)
+	}
+	if string(output) != compose(expected) {
+		t.Errorf("Expected:\n%s\nGot:\n%s", compose(expected), output)
+	}
+}
+
+func TestFixBytes_MovesCommentsWithEntries(t *testing.T) {
+	// Comments above an entry move with it, nested comments stay with the
+	// entry before them and blank lines keep their place
+	input := "services:\n" +
+		"  web:\n" +
+		"    restart: always\n" +
+		"    # the image\n" +
+		"    image: nginx\n" +
+		"    environment:\n" +
+		"      - ZZZ=1\n" +
+		"        # continues ZZZ\n" +
+		"\n" +
+		"      # about AAA\n" +
+		"      - AAA=2\n" +
+		"    labels: [zzz=1, \"aaa=2\"]\n" +
+		"    command: |\n" +
+		"      echo hi"
+
+	expected := "services:\n" +
+		"  web:\n" +
+		"    # the image\n" +
+		"    image: nginx\n" +
+		"    environment:\n" +
+		"      # about AAA\n" +
+		"      - AAA=2\n" +
+		"\n" +
+		"      - ZZZ=1\n" +
+		"        # continues ZZZ\n" +
+		"    restart: always\n" +
+		"    labels: [\"aaa=2\", zzz=1]\n" +
+		"    command: |\n" +
+		"      echo hi"
+
+	output, changes, err := reorderServices([]byte(input), config.NewDefaultConfig())
+	if err != nil {
+		t.Fatalf("reorderServices failed: %v", err)
+	}
+	if string(output) != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+	if len(changes) != 3 {
+		t.Errorf("Expected 3 changes, got %v", changes)
+	}
+}
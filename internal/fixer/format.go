@@ -0,0 +1,236 @@
+package fixer
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// normalizeFormat fixes the layout of raw YAML: nested blocks are shifted to
+// the configured indentation as a whole, trailing whitespace is removed,
+// services are separated by the configured number of blank lines and the
+// file ends with a single newline
+func normalizeFormat(data []byte, cfg *config.Config) ([]byte, []string, error) {
+	rules := cfg.Format
+	if !rules.Enabled || rules.Indent < 1 {
+		return data, nil, nil
+	}
+
+	file, err := parser.ParseBytes("", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	editor := newLineEditor(data)
+	changes := make([]string, 0)
+
+	// Shift every line by the amount its enclosing block moves
+	shifts := make(map[int]int)
+	docs := make([]*ast.DocumentNode, 0)
+	for _, doc := range file.Documents {
+		if doc != nil && doc.Body != nil {
+			docs = append(docs, doc)
+		}
+	}
+	for i, doc := range docs {
+		values, ok := parser.MappingValues(doc.Body)
+		if !ok || len(values) == 0 {
+			continue
+		}
+		// The document ends before its "..." marker or the next "---"
+		end := editor.count()
+		if doc.End != nil {
+			end = doc.End.Position.Line - 1
+		} else if i+1 < len(docs) {
+			end = docs[i+1].Body.GetToken().Position.Line - 1
+			if start := docs[i+1].Start; start != nil {
+				end = start.Position.Line - 1
+			}
+		}
+		_, column := parser.Position(values[0].Key)
+		reindentValues(values, 1-column, end, shifts, rules)
+	}
+	attachCommentShifts(editor, shifts)
+
+	blockLines := file.BlockScalarLines()
+	reindented, trimmed := 0, 0
+	for line := 1; line <= editor.count(); line++ {
+		text := editor.text(line)
+		updated := text
+		if shift := shifts[line]; shift != 0 && !isBlankLine(text) {
+			updated = shiftLine(updated, shift)
+			reindented++
+		}
+		if rules.TrailingWhitespace && !blockLines[line] {
+			if stripped := bytes.TrimRight(updated, " \t"); len(stripped) != len(updated) {
+				updated = stripped
+				trimmed++
+			}
+		}
+		if !bytes.Equal(updated, text) {
+			editor.set(line, updated)
+		}
+	}
+	if reindented > 0 {
+		changes = append(changes, fmt.Sprintf("reindented %d lines", reindented))
+	}
+	if trimmed > 0 {
+		changes = append(changes, fmt.Sprintf("removed trailing whitespace from %d lines", trimmed))
+	}
+
+	if rules.BlankLinesBetweenServices >= 0 {
+		for _, doc := range docs {
+			changes = append(changes, fixServiceSpacing(editor, doc.Body, rules.BlankLinesBetweenServices)...)
+		}
+	}
+
+	output := editor.bytes()
+	if rules.FinalNewline && len(bytes.TrimSpace(output)) > 0 {
+		if fixed := ensureFinalNewline(output); !bytes.Equal(fixed, output) {
+			output = fixed
+			changes = append(changes, "fixed final newline")
+		}
+	}
+
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+	return output, changes, nil
+}
+
+// reindentValues records the shift of every line from the first key through
+// end. Entries of one mapping move by delta; nested blocks move so that they
+// sit the configured amount deeper than their key.
+func reindentValues(values []*ast.MappingValueNode, delta, end int, shifts map[int]int, rules config.FormatRules) {
+	for i, value := range values {
+		keyLine, keyColumn := parser.Position(value.Key)
+		valueEnd := end
+		if i+1 < len(values) {
+			valueEnd = values[i+1].Key.GetToken().Position.Line - 1
+		}
+		for line := keyLine; line <= valueEnd; line++ {
+			shifts[line] = delta
+		}
+		newKeyColumn := keyColumn + delta
+
+		switch node := parser.UnwrapNode(value.Value).(type) {
+		case *ast.MappingNode, *ast.MappingValueNode:
+			children, ok := parser.MappingValues(node)
+			if !ok || len(children) == 0 {
+				continue
+			}
+			_, column := parser.Position(children[0].Key)
+			reindentValues(children, newKeyColumn+rules.Indent-column, valueEnd, shifts, rules)
+
+		case *ast.SequenceNode:
+			if node.IsFlowStyle || len(node.Values) == 0 {
+				continue
+			}
+			dashLine, dashColumn := parser.Position(node)
+			target := dashColumn + delta
+			switch rules.SequenceIndent {
+			case "indented":
+				target = newKeyColumn + rules.Indent
+			case "compact":
+				target = newKeyColumn
+			}
+			seqDelta := target - dashColumn
+			for line := dashLine; line <= valueEnd; line++ {
+				shifts[line] = seqDelta
+			}
+			for j, entry := range node.Values {
+				entryEnd := valueEnd
+				if j+1 < len(node.Values) {
+					entryEnd = node.Values[j+1].GetToken().Position.Line - 1
+				}
+				if children, ok := parser.MappingValues(parser.UnwrapNode(entry)); ok && len(children) > 0 {
+					reindentValues(children, seqDelta, entryEnd, shifts, rules)
+				}
+			}
+		}
+	}
+}
+
+// attachCommentShifts moves comment lines with the content line that follows
+// them, since comments describe what comes next
+func attachCommentShifts(editor *lineEditor, shifts map[int]int) {
+	next, hasNext := 0, false
+	for line := editor.count(); line >= 1; line-- {
+		trimmed := bytes.TrimSpace(editor.text(line))
+		switch {
+		case len(trimmed) == 0:
+		case trimmed[0] == '#':
+			if hasNext {
+				shifts[line] = next
+			}
+		default:
+			next, hasNext = shifts[line], true
+		}
+	}
+}
+
+// shiftLine adds or removes leading spaces
+func shiftLine(text []byte, shift int) []byte {
+	if shift > 0 {
+		return append([]byte(strings.Repeat(" ", shift)), text...)
+	}
+	remove := -shift
+	if spaces := leadingSpaces(text); remove > spaces {
+		remove = spaces
+	}
+	return append([]byte{}, text[remove:]...)
+}
+
+// fixServiceSpacing sets the number of blank lines before every service but
+// the first, keeping comments directly above a service attached to it
+func fixServiceSpacing(editor *lineEditor, body ast.Node, expected int) []string {
+	changes := make([]string, 0)
+	values, ok := parser.MappingValues(body)
+	if !ok {
+		return changes
+	}
+	var services []*ast.MappingValueNode
+	for _, value := range values {
+		if value.Key.String() == "services" {
+			services, _ = parser.MappingValues(parser.UnwrapNode(value.Value))
+		}
+	}
+
+	for i, service := range services {
+		if i == 0 {
+			continue
+		}
+		content, blanks := editor.gapBefore(service.Key.GetToken().Position.Line)
+		if len(blanks) == expected || content < 1 {
+			continue
+		}
+
+		for _, line := range blanks {
+			editor.delete(line)
+		}
+		editor.insertBlank(content, expected)
+		changes = append(changes, fmt.Sprintf("service '%s': set %d blank lines before service", service.Key.String(), expected))
+	}
+
+	return changes
+}
+
+// ensureFinalNewline drops trailing blank lines and terminates the last line
+func ensureFinalNewline(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for len(lines) > 0 && isBlankLine(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return data
+	}
+	out := bytes.Join(lines, nil)
+	if !bytes.HasSuffix(out, []byte("\n")) {
+		out = append(out, '\n')
+	}
+	return out
+}
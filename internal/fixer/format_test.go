@@ -0,0 +1,126 @@
+package fixer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestNormalizeFormat(t *testing.T) {
+	input := "services:\n" +
+		"    web:\n" +
+		"      image: nginx   \n" +
+		"      ports:\n" +
+		"      - \"80:80\"\n" +
+		"      command: |\n" +
+		"        echo hi   \n" +
+		"    # API service\n" +
+		"    api:\n" +
+		"        image: myapp\n" +
+		"        environment:\n" +
+		"          - KEY=value\n" +
+		"\n" +
+		"\n"
+	expected := "services:\n" +
+		"  web:\n" +
+		"    image: nginx\n" +
+		"    ports:\n" +
+		"      - \"80:80\"\n" +
+		"    command: |\n" +
+		"      echo hi   \n" +
+		"\n" +
+		"  # API service\n" +
+		"  api:\n" +
+		"    image: myapp\n" +
+		"    environment:\n" +
+		"      - KEY=value\n"
+
+	cfg := config.NewDefaultConfig()
+	cfg.Format.Enabled = true
+
+	output, changes, err := normalizeFormat([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("normalizeFormat failed: %v", err)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+	if len(changes) == 0 {
+		t.Error("Expected changes to be reported")
+	}
+
+	// A formatted file is left unchanged
+	again, changes, err := normalizeFormat(output, cfg)
+	if err != nil {
+		t.Fatalf("normalizeFormat failed: %v", err)
+	}
+	if len(changes) != 0 || string(again) != string(output) {
+		t.Errorf("Expected no changes on formatted output, got %v", changes)
+	}
+}
+
+func TestNormalizeFormat_CompactSequences(t *testing.T) {
+	input := "services:\n" +
+		"  web:\n" +
+		"    volumes:\n" +
+		"      - type: bind\n" +
+		"        source: ./data\n" +
+		"        target: /data\n"
+	expected := "services:\n" +
+		"  web:\n" +
+		"    volumes:\n" +
+		"    - type: bind\n" +
+		"      source: ./data\n" +
+		"      target: /data\n"
+
+	cfg := config.NewDefaultConfig()
+	cfg.Format.Enabled = true
+	cfg.Format.SequenceIndent = "compact"
+
+	output, _, err := normalizeFormat([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("normalizeFormat failed: %v", err)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestFixBytes_FormatMultiDocument(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join(getFixturesDir(), "multi-document.yml"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Format.Enabled = true
+	cfg.Format.Indent = 4
+
+	output, _, err := FixBytes(input, cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+	if strings.Count(string(output), "\n---\n") != 2 {
+		t.Errorf("Expected both document separators to stay unindented:\n%s", output)
+	}
+	if !strings.Contains(string(output), "\n---\n# Second document") {
+		t.Errorf("Expected the comment after the separator to stay in place:\n%s", output)
+	}
+
+	file, err := parser.ParseBytes("fixed.yml", output)
+	if err != nil {
+		t.Fatalf("Fixed file should be valid YAML: %v\n%s", err, output)
+	}
+	if len(file.Documents) != 3 {
+		t.Errorf("Expected 3 documents, got %d", len(file.Documents))
+	}
+
+	// The fixed file is stable
+	if _, changes, err := FixBytes(output, cfg); err != nil || len(changes) != 0 {
+		t.Errorf("Expected no further changes, got %v (%v)", changes, err)
+	}
+}
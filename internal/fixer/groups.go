@@ -0,0 +1,67 @@
+package fixer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// separateFieldGroups inserts a blank line between consecutive service fields
+// that belong to different field groups
+func separateFieldGroups(data []byte, cfg *config.Config) ([]byte, []string, error) {
+	if !cfg.GroupSeparators {
+		return data, nil, nil
+	}
+
+	file, err := parser.ParseBytes("", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	services := make([]parser.Service, 0)
+	for _, service := range file.GetServices() {
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Line < services[j].Line
+	})
+
+	editor := newLineEditor(data)
+	changes := make([]string, 0)
+
+	for _, service := range services {
+		serviceCfg := cfg.ForService(service.Ref())
+		groups := serviceCfg.FieldGroups
+		if len(groups) == 0 || service.Node == nil {
+			continue
+		}
+		fieldOrder := serviceCfg.FieldOrder
+
+		separated := false
+		previous := -1
+		for _, value := range service.Node.Values {
+			group := config.GroupAt(groups, config.FieldPosition(fieldOrder, value.Key.String()))
+			if group < 0 {
+				continue
+			}
+			if previous >= 0 && group != previous {
+				content, blanks := editor.gapBefore(value.Key.GetToken().Position.Line)
+				if len(blanks) == 0 && content >= 1 {
+					editor.insertBlank(content, 1)
+					separated = true
+				}
+			}
+			previous = group
+		}
+		if separated {
+			changes = append(changes, fmt.Sprintf("service '%s': separated field groups", service.Name))
+		}
+	}
+
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+	return editor.bytes(), changes, nil
+}
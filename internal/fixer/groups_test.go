@@ -0,0 +1,57 @@
+package fixer
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+)
+
+func TestSeparateFieldGroups(t *testing.T) {
+	input := `services:
+  web:
+    container_name: web
+    image: nginx
+    # Runtime settings
+    command: run
+    environment:
+      - KEY=value
+`
+	expected := `services:
+  web:
+    container_name: web
+    image: nginx
+
+    # Runtime settings
+    command: run
+    environment:
+      - KEY=value
+`
+
+	cfg := config.NewDefaultConfig()
+	cfg.GroupSeparators = true
+	cfg.FieldGroups = []config.FieldGroup{
+		{Name: "identity", Fields: []string{"container_name", "image"}},
+		{Name: "runtime", Fields: []string{"command", "environment"}},
+	}
+	cfg.FieldOrder = []string{"container_name", "image", "command", "environment"}
+
+	output, changes, err := separateFieldGroups([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("separateFieldGroups failed: %v", err)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+	if len(changes) != 1 {
+		t.Errorf("Expected 1 change, got %v", changes)
+	}
+
+	// Separated groups are left unchanged
+	_, changes, err = separateFieldGroups(output, cfg)
+	if err != nil {
+		t.Fatalf("separateFieldGroups failed: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Expected no changes on separated output, got %v", changes)
+	}
+}
@@ -0,0 +1,210 @@
+package fixer
+
+import (
+	"bytes"
+	"sort"
+)
+
+// lineEditor rewrites whole lines of raw YAML and leaves every other byte,
+// including comments and line endings, untouched
+type lineEditor struct {
+	lines   [][]byte
+	replace map[int][]byte
+	remove  map[int]bool
+	spans   map[int][]span
+	blanks  map[int]int
+	// sources maps a line to the original line moved there
+	sources map[int]int
+}
+
+// span replaces the bytes [start, end) of a line
+type span struct {
+	start, end int
+	text       string
+}
+
+// newLineEditor splits data into lines for editing
+func newLineEditor(data []byte) *lineEditor {
+	return &lineEditor{
+		lines:   bytes.SplitAfter(data, []byte("\n")),
+		replace: make(map[int][]byte),
+		remove:  make(map[int]bool),
+		spans:   make(map[int][]span),
+		blanks:  make(map[int]int),
+		sources: make(map[int]int),
+	}
+}
+
+// count returns the number of lines
+func (e *lineEditor) count() int {
+	return len(e.lines)
+}
+
+// text returns the content of a 1-based line without its line ending
+func (e *lineEditor) text(line int) []byte {
+	if line < 1 || line > len(e.lines) {
+		return nil
+	}
+	content, _ := splitLineEnding(e.lines[line-1])
+	return content
+}
+
+// set replaces the content of a 1-based line, keeping its line ending
+func (e *lineEditor) set(line int, content []byte) {
+	if line >= 1 && line <= len(e.lines) {
+		e.replace[line] = content
+	}
+}
+
+// delete removes a 1-based line including its line ending
+func (e *lineEditor) delete(line int) {
+	if line >= 1 && line <= len(e.lines) {
+		e.remove[line] = true
+	}
+}
+
+// replaceSpan replaces the bytes [start, end) of a 1-based line. Spans of one
+// line must not overlap; a whole-line replacement takes precedence.
+func (e *lineEditor) replaceSpan(line, start, end int, text string) {
+	if line >= 1 && line <= len(e.lines) {
+		e.spans[line] = append(e.spans[line], span{start: start, end: end, text: text})
+	}
+}
+
+// insertBlank adds count blank lines after a 1-based line
+func (e *lineEditor) insertBlank(line, count int) {
+	if line >= 1 && line <= len(e.lines) && count > 0 {
+		e.blanks[line] += count
+	}
+}
+
+// moveLines places the given 1-based lines, in order, at the lines from start
+// on. Lines keep their own edits when moved. Moves compose, so a range may be
+// rearranged again after lines inside it were moved.
+func (e *lineEditor) moveLines(start int, order []int) {
+	moved := make(map[int]int, len(order))
+	for i, line := range order {
+		if source, ok := e.sources[line]; ok {
+			line = source
+		}
+		moved[start+i] = line
+	}
+	for line, source := range moved {
+		if line == source {
+			delete(e.sources, line)
+		} else {
+			e.sources[line] = source
+		}
+	}
+}
+
+// gapBefore returns the last content line before a 1-based line and the blank
+// lines in between. Comment lines in the gap belong to the line below.
+func (e *lineEditor) gapBefore(line int) (int, []int) {
+	blanks := make([]int, 0)
+	content := line - 1
+	for ; content >= 1; content-- {
+		trimmed := bytes.TrimSpace(e.text(content))
+		if len(trimmed) == 0 {
+			blanks = append(blanks, content)
+			continue
+		}
+		if trimmed[0] != '#' {
+			break
+		}
+	}
+	return content, blanks
+}
+
+// changed checks if any edit was recorded
+func (e *lineEditor) changed() bool {
+	return len(e.replace) > 0 || len(e.remove) > 0 || len(e.spans) > 0 || len(e.blanks) > 0 || len(e.sources) > 0
+}
+
+// bytes assembles the edited document
+func (e *lineEditor) bytes() []byte {
+	var out bytes.Buffer
+	for i := range e.lines {
+		n := i + 1
+		if source, ok := e.sources[n]; ok {
+			n = source
+		}
+		line := e.lines[n-1]
+		content, ending := splitLineEnding(line)
+		// A line moved away from the end of the file needs an ending
+		if ending == nil && i+1 < len(e.lines) {
+			_, ending = splitLineEnding(e.lines[i])
+			if ending == nil {
+				ending = []byte("\n")
+			}
+			line = append(append([]byte{}, line...), ending...)
+		}
+		replacement, replaced := e.replace[n]
+		spans, spanned := e.spans[n]
+		switch {
+		case e.remove[n]:
+		case replaced:
+			out.Write(replacement)
+			out.Write(ending)
+		case spanned:
+			out.Write(applySpans(content, spans))
+			out.Write(ending)
+		default:
+			out.Write(line)
+		}
+
+		if count := e.blanks[n]; count > 0 {
+			if ending == nil {
+				ending = []byte("\n")
+				if !e.remove[n] {
+					out.Write(ending)
+				}
+			}
+			out.Write(bytes.Repeat(ending, count))
+		}
+	}
+	return out.Bytes()
+}
+
+// applySpans applies non-overlapping span replacements to a line
+func applySpans(content []byte, spans []span) []byte {
+	sorted := append([]span{}, spans...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].start < sorted[j].start
+	})
+
+	var out bytes.Buffer
+	last := 0
+	for _, s := range sorted {
+		if s.start < last || s.end > len(content) {
+			continue
+		}
+		out.Write(content[last:s.start])
+		out.WriteString(s.text)
+		last = s.end
+	}
+	out.Write(content[last:])
+	return out.Bytes()
+}
+
+// splitLineEnding separates a line from its "\n" or "\r\n" ending
+func splitLineEnding(line []byte) ([]byte, []byte) {
+	if bytes.HasSuffix(line, []byte("\r\n")) {
+		return line[:len(line)-2], line[len(line)-2:]
+	}
+	if bytes.HasSuffix(line, []byte("\n")) {
+		return line[:len(line)-1], line[len(line)-1:]
+	}
+	return line, nil
+}
+
+// isBlankLine checks if a line holds only whitespace
+func isBlankLine(line []byte) bool {
+	return len(bytes.TrimSpace(line)) == 0
+}
+
+// isSeparatorLine checks if a line is blank, a comment or a document marker
+func isSeparatorLine(line []byte) bool {
+	trimmed := bytes.TrimSpace(line)
+	return len(trimmed) == 0 || trimmed[0] == '#' || bytes.Equal(trimmed, []byte("---"))
+}
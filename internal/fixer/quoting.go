@@ -0,0 +1,110 @@
+package fixer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// normalizeQuoting quotes port mappings, label values and version-like
+// values, and converts quoted scalars to the configured quote style. Only
+// the scalar tokens themselves are rewritten.
+func normalizeQuoting(data []byte, cfg *config.Config) ([]byte, []string, error) {
+	rules := cfg.Style
+	if !rules.QuotePorts && !rules.QuoteLabelValues && !rules.QuoteVersions && rules.QuoteStyle == "" {
+		return data, nil, nil
+	}
+
+	file, err := parser.ParseBytes("", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	target := rules.QuoteStyle
+	if target != "single" {
+		target = "double"
+	}
+
+	editor := newLineEditor(data)
+	changes := make([]string, 0)
+	requoted := 0
+
+	for _, doc := range file.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		parser.WalkScalars(doc.Body, nil, false, func(node ast.Node, path []string, isKey bool) {
+			tk := node.GetToken()
+			line := tk.Position.Line
+			text := editor.text(line)
+			start := tk.Position.Column - 1
+			if start < 0 || start >= len(text) {
+				return
+			}
+
+			switch tk.Type {
+			case token.DoubleQuoteType, token.SingleQuoteType:
+				if rules.QuoteStyle == "" || (tk.Type == token.DoubleQuoteType) == (rules.QuoteStyle == "double") {
+					return
+				}
+				end := scalarEnd(text, start)
+				quoted, ok := quoteScalar(tk.Value, rules.QuoteStyle)
+				if end < 0 || !ok {
+					return
+				}
+				editor.replaceSpan(line, start, end, quoted)
+				requoted++
+
+			default:
+				if isKey || !needsQuotes(tk, path, rules) {
+					return
+				}
+				end := start + len(tk.Value)
+				if end > len(text) || string(text[start:end]) != tk.Value {
+					return
+				}
+				quoted, ok := quoteScalar(tk.Value, target)
+				if !ok {
+					return
+				}
+				editor.replaceSpan(line, start, end, quoted)
+				changes = append(changes, fmt.Sprintf("quoted '%s' (line %d)", tk.Value, line))
+			}
+		})
+	}
+
+	if requoted > 0 {
+		changes = append(changes, fmt.Sprintf("converted %d values to %s quotes", requoted, rules.QuoteStyle))
+	}
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+	return editor.bytes(), changes, nil
+}
+
+// needsQuotes checks if a plain scalar must be quoted
+func needsQuotes(tk *token.Token, path []string, rules config.StyleRules) bool {
+	inService := len(path) == 4 && path[0] == "services"
+	return (rules.QuotePorts && inService && path[2] == "ports") ||
+		(rules.QuoteLabelValues && inService && path[2] == "labels") ||
+		(rules.QuoteVersions && parser.IsVersionLike(tk.Value))
+}
+
+// quoteScalar writes value as a double or single quoted scalar. Values with
+// characters that need escapes cannot be single quoted.
+func quoteScalar(value, style string) (string, bool) {
+	if style == "single" {
+		for _, r := range value {
+			if r < 0x20 || r == 0x7f {
+				return "", false
+			}
+		}
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'", true
+	}
+	return strconv.Quote(value), true
+}
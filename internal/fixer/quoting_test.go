@@ -0,0 +1,116 @@
+package fixer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+	"github.com/yourusername/compose-validator/internal/validator"
+)
+
+func TestNormalizeQuoting(t *testing.T) {
+	input := `version: 3.8
+services:
+  web:
+    image: 'nginx:1.25'  # pinned
+    ports: [80:80, "443:443"]
+    labels:
+      com.example.enabled: true
+      com.example.note: 'it''s'
+    environment:
+      - PATH_SEP=a"b
+`
+	expected := `version: "3.8"
+services:
+  web:
+    image: "nginx:1.25"  # pinned
+    ports: ["80:80", "443:443"]
+    labels:
+      com.example.enabled: "true"
+      com.example.note: "it's"
+    environment:
+      - PATH_SEP=a"b
+`
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.QuotePorts = true
+	cfg.Style.QuoteLabelValues = true
+	cfg.Style.QuoteVersions = true
+	cfg.Style.QuoteStyle = "double"
+
+	output, changes, err := normalizeQuoting([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("normalizeQuoting failed: %v", err)
+	}
+	if len(changes) != 4 {
+		t.Errorf("Expected 4 changes, got %v", changes)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestNormalizeQuoting_SingleStyle(t *testing.T) {
+	input := `services:
+  web:
+    image: "nginx:1.25"
+    command: "echo \"hi\""
+    entrypoint: "printf '%s\n'"
+    ports:
+      - 8080:80
+`
+	expected := `services:
+  web:
+    image: 'nginx:1.25'
+    command: 'echo "hi"'
+    entrypoint: "printf '%s\n'"
+    ports:
+      - '8080:80'
+`
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.QuotePorts = true
+	cfg.Style.QuoteStyle = "single"
+
+	output, _, err := normalizeQuoting([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("normalizeQuoting failed: %v", err)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestFixBytes_QuotingConverges(t *testing.T) {
+	input, err := os.ReadFile(filepath.Join(getFixturesDir(), "invalid-compose.yml"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.QuotePorts = true
+	cfg.Style.QuoteStyle = "double"
+
+	output, _, err := FixBytes(input, cfg)
+	if err != nil {
+		t.Fatalf("FixBytes failed: %v", err)
+	}
+	if !strings.Contains(string(output), `- "8080:80"`) {
+		t.Errorf("Expected the port mapping to stay quoted:\n%s", output)
+	}
+
+	file, err := parser.ParseBytes("fixed.yml", output)
+	if err != nil {
+		t.Fatalf("Fixed file should be valid YAML: %v", err)
+	}
+	result, err := validator.Validate(file, cfg)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	for _, v := range result.Violations {
+		t.Errorf("Unexpected violation after fixing: %s", v.Message)
+	}
+}
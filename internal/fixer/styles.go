@@ -0,0 +1,368 @@
+package fixer
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// normalizeKeyValueStyles converts environment and labels between list and
+// map form. Each entry is rewritten on its own line, so values keep their
+// quoting and trailing comments; fields that cannot be converted line by line
+// (flow style, multi-line values, anchors) are left as they are.
+func normalizeKeyValueStyles(data []byte, cfg *config.Config) ([]byte, []string, error) {
+	if cfg.Style.Environment == "" && cfg.Style.Labels == "" {
+		return data, nil, nil
+	}
+
+	file, err := parser.ParseBytes("", data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	services := make([]parser.Service, 0)
+	for _, service := range file.GetServices() {
+		services = append(services, service)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Line < services[j].Line
+	})
+
+	editor := newLineEditor(data)
+	changes := make([]string, 0)
+
+	fields := []struct {
+		name    string
+		setting string
+	}{
+		{"environment", cfg.Style.Environment},
+		{"labels", cfg.Style.Labels},
+	}
+
+	for _, field := range fields {
+		expected := field.setting
+		if expected == "consistent" {
+			expected = ""
+			for _, service := range services {
+				if style := valueStyle(service.Config[field.name]); style != "" {
+					expected = style
+					break
+				}
+			}
+		}
+		if expected != "list" && expected != "map" {
+			continue
+		}
+
+		for _, service := range services {
+			keyIndent := fieldKeyIndent(service, field.name)
+
+			var lines map[int][]byte
+			ok := false
+			switch node := service.Field(field.name).(type) {
+			case *ast.SequenceNode:
+				if expected == "map" {
+					lines, ok = listToMap(editor, node, keyIndent)
+				}
+			case *ast.MappingNode:
+				if expected == "list" && !node.IsFlowStyle {
+					lines, ok = mapToList(editor, node.Values)
+				}
+			case *ast.MappingValueNode:
+				if expected == "list" {
+					lines, ok = mapToList(editor, []*ast.MappingValueNode{node})
+				}
+			}
+			if !ok {
+				continue
+			}
+
+			for line, content := range lines {
+				editor.set(line, content)
+			}
+			changes = append(changes, fmt.Sprintf("service '%s': converted '%s' to %s form", service.Name, field.name, expected))
+		}
+	}
+
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+	return editor.bytes(), changes, nil
+}
+
+// listToMap rewrites "- KEY=value" entries as "KEY: value" lines
+func listToMap(editor *lineEditor, seq *ast.SequenceNode, keyIndent int) (map[int][]byte, bool) {
+	if seq.IsFlowStyle || len(seq.Values) == 0 {
+		return nil, false
+	}
+
+	lines := make(map[int][]byte, len(seq.Values))
+	for _, entry := range seq.Values {
+		if _, ok := entry.(*ast.StringNode); !ok {
+			return nil, false
+		}
+		tk := entry.GetToken()
+		text := editor.text(tk.Position.Line)
+		start := tk.Position.Column - 1
+		indent := leadingSpaces(text)
+		if start > len(text) || indent >= start || text[indent] != '-' {
+			return nil, false
+		}
+
+		end := scalarEnd(text, start)
+		if end < 0 {
+			return nil, false
+		}
+		raw := string(text[start:end])
+		if tk.Type == token.StringType && raw != tk.Value {
+			return nil, false
+		}
+
+		pair, ok := listEntryToPair(raw, tk.Type)
+		if !ok {
+			return nil, false
+		}
+
+		// Map entries must be indented further than the field key
+		if indent <= keyIndent {
+			indent = keyIndent + 2
+		}
+		content := strings.Repeat(" ", indent) + pair + string(text[end:])
+		lines[tk.Position.Line] = []byte(content)
+	}
+	return lines, true
+}
+
+// listEntryToPair converts a raw "KEY=value" list entry to "KEY: value"
+func listEntryToPair(raw string, tokenType token.Type) (string, bool) {
+	switch tokenType {
+	case token.DoubleQuoteType, token.SingleQuoteType:
+		quote := raw[:1]
+		inner := raw[1 : len(raw)-1]
+		idx := strings.Index(inner, "=")
+		if idx < 0 {
+			if strings.ContainsAny(inner, `\'`) || !isPlainKey(inner) {
+				return "", false
+			}
+			return inner + ":", true
+		}
+		key := inner[:idx]
+		if strings.ContainsAny(key, `\'`) || !isPlainKey(key) {
+			return "", false
+		}
+		return key + ": " + quote + inner[idx+1:] + quote, true
+
+	case token.StringType:
+		idx := strings.Index(raw, "=")
+		if idx < 0 {
+			if !isPlainKey(raw) {
+				return "", false
+			}
+			return raw + ":", true
+		}
+		key, value := raw[:idx], raw[idx+1:]
+		if !isPlainKey(key) {
+			return "", false
+		}
+		if value == "" {
+			return key + `: ""`, true
+		}
+		if !isPlainMapValue(value) {
+			value = strconv.Quote(value)
+		}
+		return key + ": " + value, true
+	}
+	return "", false
+}
+
+// mapToList rewrites "KEY: value" entries as "- KEY=value" lines
+func mapToList(editor *lineEditor, values []*ast.MappingValueNode) (map[int][]byte, bool) {
+	if len(values) == 0 {
+		return nil, false
+	}
+
+	lines := make(map[int][]byte, len(values))
+	for _, value := range values {
+		keyToken := value.Key.GetToken()
+		if keyToken == nil || keyToken.Type != token.StringType {
+			return nil, false
+		}
+		key := keyToken.Value
+		line := keyToken.Position.Line
+		text := editor.text(line)
+		start := keyToken.Position.Column - 1
+		colon := value.GetToken().Position.Column - 1
+		if value.GetToken().Position.Line != line || start != leadingSpaces(text) ||
+			colon >= len(text) || string(text[start:colon]) != key {
+			return nil, false
+		}
+
+		rest := text[colon+1:]
+		valueStart := colon + 1 + leadingSpaces(rest)
+		entry := ""
+		end := valueStart
+
+		switch node := value.Value.(type) {
+		case *ast.NullNode:
+			if valueStart < len(text) && text[valueStart] != '#' {
+				end = scalarEnd(text, valueStart)
+			}
+			entry = key
+		case *ast.StringNode, *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode:
+			tk := node.GetToken()
+			if tk.Position.Line != line || tk.Position.Column-1 != valueStart {
+				return nil, false
+			}
+			end = scalarEnd(text, valueStart)
+			if end < 0 {
+				return nil, false
+			}
+			raw := string(text[valueStart:end])
+			switch tk.Type {
+			case token.DoubleQuoteType, token.SingleQuoteType:
+				if strings.ContainsAny(key, `\"'`) {
+					return nil, false
+				}
+				entry = raw[:1] + key + "=" + raw[1:]
+			default:
+				if raw != tk.Value {
+					return nil, false
+				}
+				entry = key + "=" + raw
+				if !isPlainListEntry(entry) {
+					entry = strconv.Quote(entry)
+				}
+			}
+		default:
+			return nil, false
+		}
+		if end < 0 {
+			return nil, false
+		}
+
+		remainder := text[end:]
+		if end == valueStart {
+			// Keep the comment of an empty value, two spaces after the entry
+			remainder = bytes.TrimLeft(text[colon+1:], " \t")
+			if len(remainder) > 0 {
+				remainder = append([]byte("  "), remainder...)
+			}
+		}
+		content := strings.Repeat(" ", start) + "- " + entry + string(remainder)
+		lines[line] = []byte(content)
+	}
+	return lines, true
+}
+
+// scalarEnd returns the offset just past the scalar starting at start, or -1
+// if a quoted scalar is not closed on this line
+func scalarEnd(text []byte, start int) int {
+	if start >= len(text) {
+		return start
+	}
+
+	switch text[start] {
+	case '"':
+		for i := start + 1; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return -1
+	case '\'':
+		for i := start + 1; i < len(text); i++ {
+			if text[i] != '\'' {
+				continue
+			}
+			if i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+		return -1
+	}
+
+	end := len(text)
+	for i := start + 1; i < len(text); i++ {
+		if text[i] == '#' && (text[i-1] == ' ' || text[i-1] == '\t') {
+			end = i
+			break
+		}
+	}
+	for end > start && (text[end-1] == ' ' || text[end-1] == '\t') {
+		end--
+	}
+	return end
+}
+
+// isPlainKey checks if s can be written as an unquoted mapping key
+func isPlainKey(s string) bool {
+	var m map[string]interface{}
+	if s == "" || yaml.Unmarshal([]byte(s+": x"), &m) != nil {
+		return false
+	}
+	_, ok := m[s]
+	return ok && len(m) == 1
+}
+
+// isPlainMapValue checks if s reads back as the same string when written as
+// an unquoted mapping value
+func isPlainMapValue(s string) bool {
+	var m map[string]interface{}
+	if yaml.Unmarshal([]byte("k: "+s), &m) != nil {
+		return false
+	}
+	value, ok := m["k"].(string)
+	return ok && value == s
+}
+
+// isPlainListEntry checks if s reads back as the same string when written as
+// an unquoted sequence entry
+func isPlainListEntry(s string) bool {
+	var list []interface{}
+	if yaml.Unmarshal([]byte("- "+s), &list) != nil || len(list) != 1 {
+		return false
+	}
+	value, ok := list[0].(string)
+	return ok && value == s
+}
+
+// leadingSpaces counts the spaces at the start of text
+func leadingSpaces(text []byte) int {
+	return len(text) - len(bytes.TrimLeft(text, " "))
+}
+
+// valueStyle returns "list" or "map" for the form of a decoded value
+func valueStyle(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return ""
+}
+
+// fieldKeyIndent returns the indentation of a service field's key
+func fieldKeyIndent(service parser.Service, field string) int {
+	if service.Node != nil {
+		for _, value := range service.Node.Values {
+			if value.Key.String() == field {
+				return value.Key.GetToken().Position.Column - 1
+			}
+		}
+	}
+	return service.Column - 1
+}
@@ -0,0 +1,97 @@
+package fixer
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+)
+
+func TestNormalizeKeyValueStyles_ListToMap(t *testing.T) {
+	input := `services:
+  web:
+    environment:
+      - "GREETING=hello world"  # quoted
+      - 'NAME=it''s'
+      - DEBUG=true
+      - PORT=8080
+      # passed through from the host
+      - HOME
+      - EMPTY=
+  api:
+    environment:
+    - COMPACT=1
+`
+	expected := `services:
+  web:
+    environment:
+      GREETING: "hello world"  # quoted
+      NAME: 'it''s'
+      DEBUG: "true"
+      PORT: "8080"
+      # passed through from the host
+      HOME:
+      EMPTY: ""
+  api:
+    environment:
+      COMPACT: "1"
+`
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.Environment = "map"
+	output, changes, err := normalizeKeyValueStyles([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("normalizeKeyValueStyles failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Errorf("Expected 2 changes, got %v", changes)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestNormalizeKeyValueStyles_MapToList(t *testing.T) {
+	input := `services:
+  web:
+    labels:
+      com.example.team: "web team"  # owner
+      com.example.tier: frontend
+      com.example.empty:
+      com.example.port: 8080
+`
+	expected := `services:
+  web:
+    labels:
+      - "com.example.team=web team"  # owner
+      - com.example.tier=frontend
+      - com.example.empty
+      - com.example.port=8080
+`
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.Labels = "list"
+	output, _, err := normalizeKeyValueStyles([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("normalizeKeyValueStyles failed: %v", err)
+	}
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+}
+
+func TestNormalizeKeyValueStyles_SkipsFlowStyle(t *testing.T) {
+	input := `services:
+  web:
+    environment: [A=1, B=2]
+`
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.Environment = "map"
+	output, changes, err := normalizeKeyValueStyles([]byte(input), cfg)
+	if err != nil {
+		t.Fatalf("normalizeKeyValueStyles failed: %v", err)
+	}
+	if len(changes) != 0 || string(output) != input {
+		t.Errorf("Flow style should be left unchanged, got:\n%s", output)
+	}
+}
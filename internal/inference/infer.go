@@ -0,0 +1,356 @@
+package inference
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+	"github.com/yourusername/compose-validator/internal/validator"
+)
+
+// alphabetizedFields are the fields whose entries can be alphabetized
+var alphabetizedFields = []string{"environment", "volumes", "labels"}
+
+// Result holds the conventions inferred from a set of compose files
+type Result struct {
+	Files    int
+	Services int
+	// FieldOrder is the field order that the fewest files fail
+	FieldOrder []string
+	// FieldCounts is the number of services using each field
+	FieldCounts map[string]int
+	// Failing is the number of files with services not following FieldOrder
+	Failing int
+	// DefaultOrder reports that the built-in field order was kept because
+	// fewer files fail it than the order inferred from the services
+	DefaultOrder bool
+	// Alphabetization holds one convention per alphabetized field
+	Alphabetization []Convention
+}
+
+// Convention describes how consistently the services alphabetize a field
+type Convention struct {
+	Field string
+	// Total is the number of services with two or more entries
+	Total int
+	// Sorted is the number of those services with sorted entries
+	Sorted int
+	// Enabled is true when enabling the rule fails no service
+	Enabled bool
+}
+
+// Infer derives a field order and alphabetization settings from existing
+// compose files, choosing the settings that make the fewest files fail
+func Infer(files []*parser.ComposeFile) (*Result, error) {
+	result := &Result{
+		Files:       len(files),
+		FieldCounts: make(map[string]int),
+	}
+
+	orders := make([][]string, 0)
+	for _, file := range files {
+		for _, service := range file.GetServices() {
+			fields := uniqueFields(service.FieldOrder)
+			for _, field := range fields {
+				result.FieldCounts[field]++
+			}
+			orders = append(orders, fields)
+			result.Services++
+		}
+	}
+
+	inferred := consensusOrder(orders)
+	failing := orderFailures(files, inferred)
+	defaultFailing := orderFailures(files, config.DefaultFieldOrder)
+	result.FieldOrder, result.Failing = inferred, failing
+	if len(inferred) == 0 || defaultFailing < failing {
+		result.FieldOrder = append([]string{}, config.DefaultFieldOrder...)
+		result.Failing = defaultFailing
+		result.DefaultOrder = true
+	}
+
+	result.Alphabetization = alphabetizationConventions(files)
+
+	return result, nil
+}
+
+// uniqueFields drops repeated fields, keeping the first occurrence, and YAML
+// merge keys, which are not fields of the service
+func uniqueFields(fields []string) []string {
+	seen := make(map[string]bool, len(fields))
+	unique := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field != "<<" && !seen[field] {
+			seen[field] = true
+			unique = append(unique, field)
+		}
+	}
+	return unique
+}
+
+// consensusOrder orders all fields so that as few pairs of fields as
+// possible appear the other way round in a service. Fields are ranked by how
+// many other fields they usually precede, ties keep the default field order
+// and then the order fields were first seen in, and adjacent fields are then
+// swapped while that agrees with more services.
+func consensusOrder(orders [][]string) []string {
+	fields := make([]string, 0)
+	firstSeen := make(map[string]int)
+	before := make(map[[2]string]int)
+	for _, order := range orders {
+		for i, field := range order {
+			if _, ok := firstSeen[field]; !ok {
+				firstSeen[field] = len(fields)
+				fields = append(fields, field)
+			}
+			for _, later := range order[i+1:] {
+				before[[2]string{field, later}]++
+			}
+		}
+	}
+
+	score := make(map[string]int, len(fields))
+	for _, a := range fields {
+		for _, b := range fields {
+			switch ab, ba := before[[2]string{a, b}], before[[2]string{b, a}]; {
+			case ab > ba:
+				score[a]++
+			case ab < ba:
+				score[a]--
+			}
+		}
+	}
+
+	defaultPosition := func(field string) int {
+		for i, f := range config.DefaultFieldOrder {
+			if f == field {
+				return i
+			}
+		}
+		return len(config.DefaultFieldOrder)
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		a, b := fields[i], fields[j]
+		if score[a] != score[b] {
+			return score[a] > score[b]
+		}
+		if pa, pb := defaultPosition(a), defaultPosition(b); pa != pb {
+			return pa < pb
+		}
+		return firstSeen[a] < firstSeen[b]
+	})
+
+	// Every swap lowers the number of disagreeing pairs, so this ends
+	for swapped := true; swapped; {
+		swapped = false
+		for i := 0; i+1 < len(fields); i++ {
+			a, b := fields[i], fields[i+1]
+			if before[[2]string{b, a}] > before[[2]string{a, b}] {
+				fields[i], fields[i+1] = b, a
+				swapped = true
+			}
+		}
+	}
+
+	return fields
+}
+
+// orderFailures returns the number of files with field order violations
+// under the given field order
+func orderFailures(files []*parser.ComposeFile, fieldOrder []string) int {
+	cfg := config.NewDefaultConfig()
+	cfg.FieldOrder = fieldOrder
+
+	failing := 0
+	for _, file := range files {
+		for name, service := range file.GetServices() {
+			if len(validator.ValidateFieldOrder(name, service, fieldOrder, cfg)) > 0 {
+				failing++
+				break
+			}
+		}
+	}
+	return failing
+}
+
+// alphabetizationConventions counts the services that alphabetize each field
+func alphabetizationConventions(files []*parser.ComposeFile) []Convention {
+	cfg := config.NewDefaultConfig()
+	cfg.Alphabetization = config.AlphabetizationRules{Environment: true, Volumes: true, Labels: true}
+
+	conventions := make([]Convention, len(alphabetizedFields))
+	for i, field := range alphabetizedFields {
+		conventions[i].Field = field
+	}
+
+	for _, file := range files {
+		for name, service := range file.GetServices() {
+			unsorted := make(map[string]bool)
+			for _, v := range validator.ValidateAlphabetization(name, service, cfg) {
+				unsorted[v.Field] = true
+			}
+
+			for i, field := range alphabetizedFields {
+				if entryCount(service.Config[field]) < 2 {
+					continue
+				}
+				conventions[i].Total++
+				if !unsorted[field] {
+					conventions[i].Sorted++
+				}
+			}
+		}
+	}
+
+	for i := range conventions {
+		conventions[i].Enabled = conventions[i].Sorted == conventions[i].Total
+	}
+	return conventions
+}
+
+// entryCount returns the number of entries of a list or mapping value
+func entryCount(value interface{}) int {
+	switch v := value.(type) {
+	case []interface{}:
+		return len(v)
+	case map[string]interface{}:
+		return len(v)
+	}
+	return 0
+}
+
+// Render writes the inferred settings as a commented config file
+func (r *Result) Render() []byte {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# Generated by compose-validator init from %s with %s.\n",
+		plural(r.Files, "file"), plural(r.Services, "service"))
+	buf.WriteString("# Fields not listed in field_order are not checked.\n\n")
+
+	if r.DefaultOrder {
+		buf.WriteString("# The default field order, which fewer files fail than an order inferred\n")
+		buf.WriteString("# from the services.\n")
+	} else {
+		buf.WriteString("# Field order inferred from the services, with the number of services\n")
+		buf.WriteString("# using each field.\n")
+	}
+	if r.Failing == 0 {
+		buf.WriteString("# All files follow this order.\n")
+	} else {
+		fmt.Fprintf(&buf, "# %d of %s do not follow this order yet; run with --fix to reorder them.\n",
+			r.Failing, plural(r.Files, "file"))
+	}
+	buf.WriteString("field_order:\n")
+	for _, field := range r.FieldOrder {
+		fmt.Fprintf(&buf, "  - %s", yamlString(field))
+		if count := r.FieldCounts[field]; count > 0 {
+			fmt.Fprintf(&buf, " # %d", count)
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString("\n# Alphabetization is enabled where every service already sorts its entries.\n")
+	buf.WriteString("alphabetization:\n")
+	for _, convention := range r.Alphabetization {
+		fmt.Fprintf(&buf, "  %s: %t # ", convention.Field, convention.Enabled)
+		if convention.Total == 0 {
+			fmt.Fprintf(&buf, "no service has several %s entries\n", convention.Field)
+			continue
+		}
+		fmt.Fprintf(&buf, "%d of %s sorted\n", convention.Sorted, plural(convention.Total, "service"))
+	}
+
+	return buf.Bytes()
+}
+
+// plural formats a count with a noun, adding an s unless the count is one
+func plural(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// yamlString formats a field name as a YAML scalar, quoting it if needed
+func yamlString(s string) string {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// composeFilePatterns match the names of compose files found in directories
+var composeFilePatterns = []string{
+	"compose.yaml", "compose.yml",
+	"compose.*.yaml", "compose.*.yml",
+	"docker-compose.yaml", "docker-compose.yml",
+	"docker-compose.*.yaml", "docker-compose.*.yml",
+}
+
+// FindComposeFiles returns the compose files for the given paths: files and
+// glob matches are used as they are, directories are searched recursively
+// for files with compose file names, skipping hidden directories
+func FindComposeFiles(paths []string) ([]string, error) {
+	files := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, pattern := range paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			err = filepath.WalkDir(match, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					if path != match && strings.HasPrefix(d.Name(), ".") {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if isComposeFileName(d.Name()) {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// isComposeFileName checks if a file name is one compose tools pick up
+func isComposeFileName(name string) bool {
+	for _, pattern := range composeFilePatterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package inference
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func parseFiles(t *testing.T, contents ...string) []*parser.ComposeFile {
+	t.Helper()
+	files := make([]*parser.ComposeFile, 0, len(contents))
+	for i, content := range contents {
+		file, err := parser.ParseBytes(filepath.Join("dir", string(rune('a'+i))+".yml"), []byte(content))
+		if err != nil {
+			t.Fatalf("Failed to parse: %v", err)
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+func TestInfer_FieldOrder(t *testing.T) {
+	files := parseFiles(t, `services:
+  web:
+    image: nginx
+    container_name: web
+    ports:
+      - "80:80"
+    restart: always
+`, `services:
+  api:
+    image: api
+    container_name: api
+    restart: always
+`, `services:
+  db:
+    container_name: db
+    image: postgres
+    restart: always
+`)
+
+	result, err := Infer(files)
+	if err != nil {
+		t.Fatalf("Infer failed: %v", err)
+	}
+
+	expected := []string{"image", "container_name", "ports", "restart"}
+	if !reflect.DeepEqual(result.FieldOrder, expected) {
+		t.Errorf("Expected field order %v, got %v", expected, result.FieldOrder)
+	}
+	if result.DefaultOrder {
+		t.Error("Expected the inferred order to be used")
+	}
+	if result.Failing != 1 {
+		t.Errorf("Expected 1 failing file, got %d", result.Failing)
+	}
+	if result.Files != 3 || result.Services != 3 {
+		t.Errorf("Expected 3 files and 3 services, got %d and %d", result.Files, result.Services)
+	}
+	if result.FieldCounts["image"] != 3 || result.FieldCounts["ports"] != 1 {
+		t.Errorf("Unexpected field counts: %v", result.FieldCounts)
+	}
+}
+
+func TestInfer_MergeKeys(t *testing.T) {
+	files := parseFiles(t, `x-defaults: &defaults
+  restart: always
+
+services:
+  web:
+    <<: *defaults
+    image: nginx
+    container_name: web
+  api:
+    <<: *defaults
+    image: api
+    container_name: api
+`)
+
+	result, err := Infer(files)
+	if err != nil {
+		t.Fatalf("Infer failed: %v", err)
+	}
+
+	for _, field := range result.FieldOrder {
+		if field == "<<" {
+			t.Errorf("Expected merge keys to be left out, got %v", result.FieldOrder)
+		}
+	}
+	if _, ok := result.FieldCounts["<<"]; ok {
+		t.Errorf("Expected no count for merge keys, got %v", result.FieldCounts)
+	}
+}
+
+func TestInfer_DefaultOrder(t *testing.T) {
+	// The services disagree on fields the default order does not list
+	files := parseFiles(t, `services:
+  web:
+    container_name: web
+    image: nginx
+    command: run
+    working_dir: /app
+`, `services:
+  api:
+    container_name: api
+    image: api
+    working_dir: /app
+    command: run
+`)
+
+	result, err := Infer(files)
+	if err != nil {
+		t.Fatalf("Infer failed: %v", err)
+	}
+
+	if !result.DefaultOrder {
+		t.Errorf("Expected the default order to be kept, got %v", result.FieldOrder)
+	}
+	if result.Failing != 0 {
+		t.Errorf("Expected no failing files, got %d", result.Failing)
+	}
+	if !reflect.DeepEqual(result.FieldOrder, config.DefaultFieldOrder) {
+		t.Errorf("Expected the default field order, got %v", result.FieldOrder)
+	}
+}
+
+func TestInfer_Alphabetization(t *testing.T) {
+	files := parseFiles(t, `services:
+  web:
+    image: nginx
+    environment:
+      - A=1
+      - B=2
+    volumes:
+      - ./b:/b
+      - ./a:/a
+`, `services:
+  api:
+    image: api
+    environment:
+      - C=1
+      - D=2
+    volumes:
+      - ./a:/a
+      - ./b:/b
+    labels:
+      - single=1
+`)
+
+	result, err := Infer(files)
+	if err != nil {
+		t.Fatalf("Infer failed: %v", err)
+	}
+
+	expected := []Convention{
+		{Field: "environment", Total: 2, Sorted: 2, Enabled: true},
+		{Field: "volumes", Total: 2, Sorted: 1, Enabled: false},
+		{Field: "labels", Total: 0, Sorted: 0, Enabled: true},
+	}
+	if !reflect.DeepEqual(result.Alphabetization, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result.Alphabetization)
+	}
+}
+
+func TestInfer_AlphabetizationMaps(t *testing.T) {
+	files := parseFiles(t, `services:
+  web:
+    image: nginx
+    environment:
+      A: "1"
+      B: "2"
+      C: "3"
+    labels:
+      zzz: "1"
+      aaa: "2"
+      mmm: "3"
+`)
+
+	// Mapping keys are checked in file order, so every run agrees
+	for i := 0; i < 20; i++ {
+		result, err := Infer(files)
+		if err != nil {
+			t.Fatalf("Infer failed: %v", err)
+		}
+		expected := []Convention{
+			{Field: "environment", Total: 1, Sorted: 1, Enabled: true},
+			{Field: "volumes", Total: 0, Sorted: 0, Enabled: true},
+			{Field: "labels", Total: 1, Sorted: 0, Enabled: false},
+		}
+		if !reflect.DeepEqual(result.Alphabetization, expected) {
+			t.Fatalf("Expected %+v, got %+v", expected, result.Alphabetization)
+		}
+	}
+}
+
+func TestRender_LoadsAsConfig(t *testing.T) {
+	files := parseFiles(t, `services:
+  web:
+    image: nginx
+    container_name: web
+    x-team: core
+    environment:
+      - B=1
+      - A=2
+`)
+
+	result, err := Infer(files)
+	if err != nil {
+		t.Fatalf("Infer failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), ".compose-validator.yaml")
+	if err := os.WriteFile(path, result.Render(), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := config.LoadFromFile(path)
+	if err != nil {
+		t.Fatalf("Generated config does not load: %v\n%s", err, result.Render())
+	}
+	if !reflect.DeepEqual([]string(cfg.FieldOrder), result.FieldOrder) {
+		t.Errorf("Expected field order %v, got %v", result.FieldOrder, cfg.FieldOrder)
+	}
+	if cfg.Alphabetization.Environment {
+		t.Error("Expected environment alphabetization to be disabled")
+	}
+}
+
+func TestFindComposeFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"docker-compose.yml",
+		"app/compose.yaml",
+		"app/compose.prod.yml",
+		"app/config.yml",
+		".git/compose.yaml",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("services: {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := FindComposeFiles([]string{dir, filepath.Join(dir, "app", "config.yml")})
+	if err != nil {
+		t.Fatalf("FindComposeFiles failed: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(dir, "app", "compose.prod.yml"),
+		filepath.Join(dir, "app", "compose.yaml"),
+		filepath.Join(dir, "docker-compose.yml"),
+		filepath.Join(dir, "app", "config.yml"),
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+}
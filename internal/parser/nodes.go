@@ -0,0 +1,100 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+)
+
+// versionLikePattern matches dotted numbers such as 3.8 or 1.10.2
+var versionLikePattern = regexp.MustCompile(`^\d+(\.\d+)+$`)
+
+// IsVersionLike checks if a value is a dotted number such as 3.8, which YAML
+// reads as a float unless it is quoted
+func IsVersionLike(value string) bool {
+	return versionLikePattern.MatchString(value)
+}
+
+// WalkScalars calls visit for every scalar key and value under node with the
+// key path leading to it. Sequence entries are addressed by index.
+func WalkScalars(node ast.Node, path []string, isKey bool, visit func(ast.Node, []string, bool)) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			WalkScalars(value, path, false, visit)
+		}
+	case *ast.MappingValueNode:
+		keyPath := append(append([]string{}, path...), n.Key.String())
+		WalkScalars(n.Key, keyPath, true, visit)
+		WalkScalars(n.Value, keyPath, false, visit)
+	case *ast.SequenceNode:
+		for i, value := range n.Values {
+			WalkScalars(value, append(append([]string{}, path...), strconv.Itoa(i)), false, visit)
+		}
+	case *ast.AnchorNode:
+		WalkScalars(n.Value, path, isKey, visit)
+	case *ast.TagNode:
+		WalkScalars(n.Value, path, isKey, visit)
+	case *ast.StringNode, *ast.IntegerNode, *ast.FloatNode, *ast.BoolNode:
+		if n.GetToken() != nil {
+			visit(n, path, isKey)
+		}
+	}
+}
+
+// MappingValues returns the entries of a block or single-entry mapping
+func MappingValues(node ast.Node) ([]*ast.MappingValueNode, bool) {
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		if n.IsFlowStyle {
+			return nil, false
+		}
+		return n.Values, true
+	case *ast.MappingValueNode:
+		return []*ast.MappingValueNode{n}, true
+	}
+	return nil, false
+}
+
+// UnwrapNode skips anchor and tag wrappers
+func UnwrapNode(node ast.Node) ast.Node {
+	for {
+		switch n := node.(type) {
+		case *ast.AnchorNode:
+			node = n.Value
+		case *ast.TagNode:
+			node = n.Value
+		default:
+			return node
+		}
+	}
+}
+
+// BlockScalarLines returns the lines holding literal or folded block scalar
+// content, whose whitespace is part of the value
+func (cf *ComposeFile) BlockScalarLines() map[int]bool {
+	lines := make(map[int]bool)
+	for _, doc := range cf.Documents {
+		if doc == nil {
+			continue
+		}
+		ast.Walk(blockScalarVisitor(lines), doc)
+	}
+	return lines
+}
+
+// blockScalarVisitor records block scalar content lines while walking the AST
+type blockScalarVisitor map[int]bool
+
+// Visit implements ast.Visitor
+func (v blockScalarVisitor) Visit(node ast.Node) ast.Visitor {
+	if literal, ok := node.(*ast.LiteralNode); ok && literal.Value != nil {
+		tk := literal.Value.GetToken()
+		for i := 0; i < strings.Count(tk.Origin, "\n"); i++ {
+			v[tk.Position.Line+i] = true
+		}
+	}
+	return v
+}
@@ -3,10 +3,13 @@ package parser
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/goccy/go-yaml/ast"
 	"github.com/goccy/go-yaml/parser"
+	"github.com/yourusername/compose-validator/internal/config"
 )
 
 // ComposeFile represents a parsed Docker Compose file
@@ -22,6 +25,8 @@ type Service struct {
 	Name       string
 	Config     map[string]interface{}
 	FieldOrder []string // Original field order from YAML
+	// Node is the service mapping in the AST (nil for hand-built services)
+	Node *ast.MappingNode
 	// Position information
 	Line   int
 	Column int
@@ -39,7 +44,8 @@ func ParseFile(path string) (*ComposeFile, error) {
 
 // ParseBytes parses Docker Compose YAML from bytes
 func ParseBytes(path string, data []byte) (*ComposeFile, error) {
-	file, err := parser.ParseBytes(data, parser.ParseComments)
+	// Duplicate keys are kept in the AST so the validator can report them
+	file, err := parser.ParseBytes(data, parser.ParseComments, parser.AllowDuplicateMapKey())
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse YAML in %s: %w", path, err)
 	}
@@ -102,7 +108,7 @@ func (cf *ComposeFile) GetServices() map[string]Service {
 
 				// Decode the value using yaml.Unmarshal
 				var value interface{}
-				if err := yaml.Unmarshal([]byte(field.Value.String()), &value); err == nil {
+				if err := yaml.UnmarshalWithOptions([]byte(field.Value.String()), &value, yaml.AllowDuplicateMapKey()); err == nil {
 					svcConfig[fieldName] = value
 				} else {
 					// Fallback: try to decode as string
@@ -114,6 +120,7 @@ func (cf *ComposeFile) GetServices() map[string]Service {
 				Name:       svcName,
 				Config:     svcConfig,
 				FieldOrder: fieldOrder,
+				Node:       svcMapping,
 				Line:       svcVal.Key.GetToken().Position.Line,
 				Column:     svcVal.Key.GetToken().Position.Column,
 			}
@@ -123,6 +130,48 @@ func (cf *ComposeFile) GetServices() map[string]Service {
 	return services
 }
 
+// ProjectName returns the Compose project name: the top-level `name` of the
+// first document declaring one, otherwise the normalized directory name
+func (cf *ComposeFile) ProjectName() string {
+	for _, doc := range cf.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		mapping, ok := doc.Body.(*ast.MappingNode)
+		if !ok {
+			continue
+		}
+		for _, val := range mapping.Values {
+			if val.Key.String() != "name" {
+				continue
+			}
+			if scalar, ok := val.Value.(ast.ScalarNode); ok {
+				if name, ok := scalar.GetValue().(string); ok && name != "" {
+					return name
+				}
+			}
+		}
+	}
+
+	dir := filepath.Base(filepath.Dir(cf.Path))
+	if abs, err := filepath.Abs(filepath.Dir(cf.Path)); err == nil {
+		dir = filepath.Base(abs)
+	}
+	return normalizeProjectName(dir)
+}
+
+// normalizeProjectName lowercases name and drops characters Compose does not
+// allow in project names
+func normalizeProjectName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '_' || r == '-' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimLeft(b.String(), "_-")
+}
+
 // GetDocumentContent returns the content of a document as a map
 func (cf *ComposeFile) GetDocumentContent(doc *ast.DocumentNode) (map[string]interface{}, error) {
 	if doc == nil || doc.Body == nil {
@@ -136,3 +185,81 @@ func (cf *ComposeFile) GetDocumentContent(doc *ast.DocumentNode) (map[string]int
 
 	return content, nil
 }
+
+// Field returns the AST value node of a service field, or nil if the field
+// is absent or the service was not built from an AST
+func (s Service) Field(name string) ast.Node {
+	if s.Node == nil {
+		return nil
+	}
+	for _, field := range s.Node.Values {
+		if field.Key.String() == name {
+			return field.Value
+		}
+	}
+	return nil
+}
+
+// Keys returns the keys of a mapping field in file order, or nil if the field
+// is not a mapping or the service was not built from an AST. The decoded
+// Config holds mappings as Go maps, which do not keep key order.
+func (s Service) Keys(name string) []string {
+	var values []*ast.MappingValueNode
+	switch n := UnwrapNode(s.Field(name)).(type) {
+	case *ast.MappingNode:
+		values = n.Values
+	case *ast.MappingValueNode:
+		values = []*ast.MappingValueNode{n}
+	default:
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for _, value := range values {
+		keys = append(keys, UnwrapNode(value.Key).GetToken().Value)
+	}
+	return keys
+}
+
+// Ref returns what service_overrides match the service by
+func (s Service) Ref() config.ServiceRef {
+	return config.ServiceRef{Name: s.Name, Image: s.Image(), Labels: s.Labels()}
+}
+
+// Image returns the image of a service, or an empty string
+func (s Service) Image() string {
+	image, _ := s.Config["image"].(string)
+	return image
+}
+
+// Labels returns the labels of a service, written in list or map form
+func (s Service) Labels() map[string]string {
+	labels := make(map[string]string)
+	switch v := s.Config["labels"].(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if value == nil {
+				labels[key] = ""
+			} else {
+				labels[key] = fmt.Sprint(value)
+			}
+		}
+	case []interface{}:
+		for _, entry := range v {
+			if s, ok := entry.(string); ok {
+				key, value, _ := strings.Cut(s, "=")
+				labels[key] = value
+			}
+		}
+	}
+	return labels
+}
+
+// Position returns the line and column at which a node starts
+func Position(node ast.Node) (int, int) {
+	if node == nil || node.GetToken() == nil {
+		return 0, 0
+	}
+	pos := node.GetToken().Position
+	return pos.Line, pos.Column
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/goccy/go-yaml/ast"
 )
 
 func TestParseBytes_ValidSingleDocument(t *testing.T) {
@@ -310,3 +313,97 @@ services:
 		t.Errorf("Expected 1 field in order, got %v", empty.FieldOrder)
 	}
 }
+
+func TestProjectName(t *testing.T) {
+	file, err := ParseBytes("/srv/My_Project.v2/docker-compose.yml", []byte("services:\n  web:\n    image: nginx\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name := file.ProjectName(); name != "my_projectv2" {
+		t.Errorf("Expected project name from directory 'my_projectv2', got '%s'", name)
+	}
+
+	file, err = ParseBytes("/srv/app/docker-compose.yml", []byte("name: shop\nservices:\n  web:\n    image: nginx\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name := file.ProjectName(); name != "shop" {
+		t.Errorf("Expected project name 'shop', got '%s'", name)
+	}
+}
+
+func TestService_ImageAndLabels(t *testing.T) {
+	yaml := `services:
+  web:
+    image: nginx:1.25
+    labels:
+      - com.example.tier=frontend
+      - com.example.flag
+  db:
+    labels:
+      com.example.tier: backend
+      com.example.port: 5432
+`
+	file, err := ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	services := file.GetServices()
+
+	web := services["web"]
+	if web.Image() != "nginx:1.25" {
+		t.Errorf("Expected image nginx:1.25, got %q", web.Image())
+	}
+	if labels := web.Labels(); labels["com.example.tier"] != "frontend" || len(labels) != 2 {
+		t.Errorf("Unexpected list labels: %v", labels)
+	}
+
+	db := services["db"]
+	if db.Image() != "" {
+		t.Errorf("Expected no image, got %q", db.Image())
+	}
+	if labels := db.Labels(); labels["com.example.tier"] != "backend" || labels["com.example.port"] != "5432" {
+		t.Errorf("Unexpected map labels: %v", labels)
+	}
+}
+
+func TestWalkScalars(t *testing.T) {
+	yaml := `services:
+  web:
+    image: nginx
+    ports:
+      - "80:80"
+    command: |
+      run
+      now
+    restart: always
+`
+	file, err := ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	paths := make([]string, 0)
+	WalkScalars(file.Documents[0].Body, nil, false, func(node ast.Node, path []string, isKey bool) {
+		if !isKey {
+			paths = append(paths, strings.Join(path, "."))
+		}
+	})
+	expected := []string{"services.web.image", "services.web.ports.0", "services.web.restart"}
+	if strings.Join(paths, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected scalar values at %v, got %v", expected, paths)
+	}
+
+	lines := file.BlockScalarLines()
+	if !lines[7] || !lines[8] || lines[6] {
+		t.Errorf("Expected block scalar content on lines 7 and 8, got %v", lines)
+	}
+}
+
+func TestIsVersionLike(t *testing.T) {
+	for value, expected := range map[string]bool{"3.8": true, "1.10.2": true, "3": false, "v3.8": false, "3.": false} {
+		if IsVersionLike(value) != expected {
+			t.Errorf("IsVersionLike(%q) = %v, expected %v", value, !expected, expected)
+		}
+	}
+}
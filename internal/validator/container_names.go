@@ -0,0 +1,142 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// containerNameOwner ties a container_name to the service and file declaring it
+type containerNameOwner struct {
+	name    string
+	file    string
+	service string
+	line    int
+	column  int
+}
+
+// validateContainerNames checks container_name uniqueness within a file and
+// the configured naming convention
+func validateContainerNames(file *parser.ComposeFile, services map[string]parser.Service, cfg *config.Config) ([]Violation, error) {
+	violations := make([]Violation, 0)
+	owners := collectContainerNames(file.Path, services)
+
+	if cfg.ContainerNames.Unique {
+		for i, owner := range owners {
+			for _, previous := range owners[:i] {
+				if previous.name == owner.name {
+					violations = append(violations, containerNameConflict(owner, previous, false))
+					break
+				}
+			}
+		}
+	}
+
+	if cfg.ContainerNames.Pattern != "" {
+		project := file.ProjectName()
+		for _, owner := range owners {
+			pattern, err := containerNamePattern(cfg.ContainerNames.Pattern, project, owner.service)
+			if err != nil {
+				return nil, err
+			}
+			if !pattern.MatchString(owner.name) {
+				violations = append(violations, Violation{
+					Type:     "container_name",
+					Service:  owner.service,
+					Field:    "container_name",
+					Message:  fmt.Sprintf("container name '%s' does not match pattern '%s'", owner.name, cfg.ContainerNames.Pattern),
+					Expected: cfg.ContainerNames.Pattern,
+					Actual:   owner.name,
+					Line:     owner.line,
+					Column:   owner.column,
+				})
+			}
+		}
+	}
+
+	return violations, nil
+}
+
+// validateContainerNamesAcrossFiles reports container names reused between
+// files. A service redeclaring its own container name in another file is an
+// override, not a conflict.
+func validateContainerNamesAcrossFiles(files []*parser.ComposeFile, resultsByFile map[string]*ValidationResult) {
+	owners := make([]containerNameOwner, 0)
+	for _, file := range files {
+		owners = append(owners, collectContainerNames(file.Path, file.GetServices())...)
+	}
+
+	for i, owner := range owners {
+		for _, previous := range owners[:i] {
+			if previous.file == owner.file || previous.service == owner.service {
+				continue
+			}
+			if previous.name == owner.name {
+				result := resultsByFile[owner.file]
+				result.Violations = append(result.Violations, containerNameConflict(owner, previous, true))
+				break
+			}
+		}
+	}
+}
+
+// collectContainerNames returns the container names of services in source order
+func collectContainerNames(file string, services map[string]parser.Service) []containerNameOwner {
+	owners := make([]containerNameOwner, 0)
+	for _, service := range servicesInOrder(services) {
+		name, ok := service.Config["container_name"].(string)
+		if !ok || name == "" {
+			continue
+		}
+
+		line, column := service.Line, service.Column
+		if node := service.Field("container_name"); node != nil {
+			line, column = parser.Position(node)
+		}
+
+		owners = append(owners, containerNameOwner{
+			name:    name,
+			file:    file,
+			service: service.Name,
+			line:    line,
+			column:  column,
+		})
+	}
+	return owners
+}
+
+// containerNameConflict describes owner reusing the name of an earlier service
+func containerNameConflict(owner, previous containerNameOwner, otherFile bool) Violation {
+	where := fmt.Sprintf("line %d", previous.line)
+	if otherFile {
+		where = fmt.Sprintf("%s:%d", previous.file, previous.line)
+	}
+
+	return Violation{
+		Type:    "container_name",
+		Service: owner.service,
+		Field:   "container_name",
+		Message: fmt.Sprintf("container name '%s' is already used by service '%s' (%s)", owner.name, previous.service, where),
+		Actual:  owner.name,
+		Line:    owner.line,
+		Column:  owner.column,
+	}
+}
+
+// containerNamePattern compiles a naming convention for one service,
+// substituting the {project} and {service} placeholders
+func containerNamePattern(pattern, project, service string) (*regexp.Regexp, error) {
+	expanded := strings.NewReplacer(
+		"{project}", regexp.QuoteMeta(project),
+		"{service}", regexp.QuoteMeta(service),
+	).Replace(pattern)
+
+	re, err := regexp.Compile("^(?:" + expanded + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid container name pattern '%s': %w", pattern, err)
+	}
+	return re, nil
+}
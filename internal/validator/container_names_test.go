@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidate_DuplicateContainerName(t *testing.T) {
+	yaml := `
+services:
+  web:
+    container_name: app
+    image: nginx:latest
+  api:
+    container_name: app
+    image: myapp:latest
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	result, err := Validate(file, config.NewDefaultConfig())
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	names := violationsOfType(result.Violations, "container_name")
+	if len(names) != 1 {
+		t.Fatalf("Expected 1 container_name violation, got %d: %v", len(names), names)
+	}
+	if names[0].Service != "api" || names[0].Line != 7 {
+		t.Errorf("Expected violation on 'api' line 7, got '%s' line %d", names[0].Service, names[0].Line)
+	}
+}
+
+func TestValidate_ContainerNamePattern(t *testing.T) {
+	yaml := `
+name: shop
+services:
+  web:
+    container_name: shop-web
+  api:
+    container_name: backend
+  worker:
+    container_name: shop-worker-1
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.ContainerNames.Pattern = `{project}-{service}(-\d+)?`
+
+	result, err := Validate(file, cfg)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	names := violationsOfType(result.Violations, "container_name")
+	if len(names) != 1 {
+		t.Fatalf("Expected 1 container_name violation, got %d: %v", len(names), names)
+	}
+	if names[0].Service != "api" || names[0].Actual != "backend" {
+		t.Errorf("Expected violation for 'api' named 'backend', got %+v", names[0])
+	}
+}
+
+func TestValidate_ContainerNamePatternInvalid(t *testing.T) {
+	file, err := parser.ParseBytes("test.yml", []byte("services:\n  web:\n    container_name: web\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.ContainerNames.Pattern = "{service}(["
+
+	if _, err := Validate(file, cfg); err == nil || !strings.Contains(err.Error(), "invalid container name pattern") {
+		t.Errorf("Expected invalid pattern error, got %v", err)
+	}
+}
+
+func TestValidateAcrossFiles_ContainerNames(t *testing.T) {
+	base, err := parser.ParseBytes("docker-compose.yml", []byte(`
+services:
+  web:
+    container_name: web
+  db:
+    container_name: database
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	other, err := parser.ParseBytes("other/docker-compose.yml", []byte(`
+services:
+  web:
+    container_name: web
+  postgres:
+    container_name: database
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	files := []*parser.ComposeFile{base, other}
+
+	for _, result := range ValidateAcrossFiles(files, []*config.Config{cfg, cfg}) {
+		if !result.Valid {
+			t.Errorf("Expected no cross-file checks by default, got %v", result.Violations)
+		}
+	}
+
+	cfg.ContainerNames.AcrossFiles = true
+	results := ValidateAcrossFiles(files, []*config.Config{cfg, cfg})
+	if !results[0].Valid {
+		t.Errorf("Expected first file to be valid, got %v", results[0].Violations)
+	}
+
+	// 'web' redeclared by the same service is an override, 'database' is a conflict
+	if len(results[1].Violations) != 1 || results[1].Violations[0].Service != "postgres" {
+		t.Errorf("Expected 1 conflict on 'postgres', got %v", results[1].Violations)
+	}
+}
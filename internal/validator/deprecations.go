@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// deprecatedServiceFields maps legacy service fields to their replacement advice
+var deprecatedServiceFields = []struct {
+	field   string
+	enabled func(config.DeprecationRules) bool
+	advice  string
+}{
+	{"links", func(r config.DeprecationRules) bool { return r.Links }, "services on a shared network reach each other by service name"},
+	{"external_links", func(r config.DeprecationRules) bool { return r.ExternalLinks }, "attach the service to an external network instead"},
+	{"volumes_from", func(r config.DeprecationRules) bool { return r.VolumesFrom }, "share data through named volumes instead"},
+}
+
+// validateDeprecations reports obsolete Compose syntax: the top-level version
+// key, legacy service fields and container_name on replicated services
+func validateDeprecations(file *parser.ComposeFile, services map[string]parser.Service, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+	rules := cfg.Deprecations
+	if !rules.Enabled {
+		return violations
+	}
+
+	if rules.Version {
+		for _, key := range versionKeys(file) {
+			line, column := parser.Position(key)
+			violations = append(violations, Violation{
+				Type:    "deprecated",
+				Field:   "version",
+				Message: "top-level 'version' is obsolete and ignored by Compose; remove it",
+				Actual:  "version",
+				Line:    line,
+				Column:  column,
+			})
+		}
+	}
+
+	for _, service := range servicesInOrder(services) {
+		for _, deprecated := range deprecatedServiceFields {
+			if _, ok := service.Config[deprecated.field]; !ok || !deprecated.enabled(rules) {
+				continue
+			}
+			line, column := fieldKeyPosition(service, deprecated.field)
+			violations = append(violations, Violation{
+				Type:    "deprecated",
+				Service: service.Name,
+				Field:   deprecated.field,
+				Message: fmt.Sprintf("'%s' is a legacy feature; %s", deprecated.field, deprecated.advice),
+				Actual:  deprecated.field,
+				Line:    line,
+				Column:  column,
+			})
+		}
+
+		if rules.ContainerNameReplicas {
+			if _, ok := service.Config["container_name"]; ok {
+				if replicas, ok := deployReplicas(service); ok && replicas > 1 {
+					line, column := fieldKeyPosition(service, "container_name")
+					violations = append(violations, Violation{
+						Type:    "deprecated",
+						Service: service.Name,
+						Field:   "container_name",
+						Message: fmt.Sprintf("container_name cannot be combined with deploy.replicas (%d); container names must be unique", replicas),
+						Actual:  "container_name",
+						Line:    line,
+						Column:  column,
+					})
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// versionKeys returns the key node of every top-level version entry
+func versionKeys(file *parser.ComposeFile) []ast.Node {
+	keys := make([]ast.Node, 0)
+	for _, doc := range file.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		var values []*ast.MappingValueNode
+		switch body := doc.Body.(type) {
+		case *ast.MappingNode:
+			values = body.Values
+		case *ast.MappingValueNode:
+			values = []*ast.MappingValueNode{body}
+		}
+		for _, value := range values {
+			if value.Key.String() == "version" {
+				keys = append(keys, value.Key)
+			}
+		}
+	}
+	return keys
+}
+
+// deployReplicas returns deploy.replicas of a service, if set to a number
+func deployReplicas(service parser.Service) (int, bool) {
+	deploy, ok := service.Config["deploy"].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	switch v := deploy["replicas"].(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,110 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidateDeprecations(t *testing.T) {
+	yaml := `version: '3.8'
+
+services:
+  web:
+    image: nginx:latest
+    links:
+      - api
+  api:
+    container_name: api
+    image: myapp:latest
+    deploy:
+      replicas: 3
+  worker:
+    image: myapp:latest
+    external_links:
+      - redis_1
+    volumes_from:
+      - api
+  single:
+    container_name: single
+    image: myapp:latest
+    deploy:
+      replicas: 1
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	if v := validateDeprecations(file, file.GetServices(), cfg); len(v) != 0 {
+		t.Errorf("Expected no violations while disabled, got %d", len(v))
+	}
+
+	cfg.Deprecations.Enabled = true
+	violations := validateDeprecations(file, file.GetServices(), cfg)
+
+	expected := []struct {
+		service string
+		field   string
+		line    int
+	}{
+		{"", "version", 1},
+		{"web", "links", 6},
+		{"api", "container_name", 9},
+		{"worker", "external_links", 15},
+		{"worker", "volumes_from", 17},
+	}
+
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d violations, got %d: %+v", len(expected), len(violations), violations)
+	}
+	for i, e := range expected {
+		v := violations[i]
+		if v.Type != "deprecated" || v.Service != e.service || v.Field != e.field || v.Line != e.line {
+			t.Errorf("Violation %d: expected %s/%s at line %d, got %+v", i, e.service, e.field, e.line, v)
+		}
+	}
+
+	cfg.Deprecations.Version = false
+	cfg.Deprecations.Links = false
+	violations = validateDeprecations(file, file.GetServices(), cfg)
+	for _, v := range violations {
+		if v.Field == "version" || v.Field == "links" {
+			t.Errorf("Disabled check reported: %+v", v)
+		}
+	}
+}
+
+func TestValidateDeprecations_MultiDocument(t *testing.T) {
+	yaml := `version: '3.8'
+services:
+  web:
+    image: nginx:latest
+---
+version: '3.8'
+networks:
+  frontend: {}
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Deprecations.Enabled = true
+	violations := violationsOfType(validateDeprecations(file, file.GetServices(), cfg), "deprecated")
+
+	versions := 0
+	for _, v := range violations {
+		if v.Field == "version" {
+			versions++
+		}
+	}
+	if versions != 2 {
+		t.Errorf("Expected a version violation per document, got %d", versions)
+	}
+}
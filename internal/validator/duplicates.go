@@ -0,0 +1,154 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// validateDuplicateKeys walks every document and reports mapping keys that
+// are defined more than once in the same mapping
+func validateDuplicateKeys(file *parser.ComposeFile) []Violation {
+	violations := make([]Violation, 0)
+	for _, doc := range file.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		violations = append(violations, findDuplicateKeys(doc.Body, nil)...)
+	}
+	return violations
+}
+
+// findDuplicateKeys recursively checks node, tracking the key path so that
+// violations under services can be attributed to their service
+func findDuplicateKeys(node ast.Node, path []string) []Violation {
+	violations := make([]Violation, 0)
+
+	switch n := node.(type) {
+	case *ast.MappingNode:
+		seen := make(map[string]*ast.MappingValueNode, len(n.Values))
+		for _, value := range n.Values {
+			key := value.Key.String()
+			if first, ok := seen[key]; ok && key != "<<" {
+				firstLine, _ := parser.Position(first.Key)
+				line, column := parser.Position(value.Key)
+				violations = append(violations, Violation{
+					Type:    "duplicate",
+					Service: serviceForPath(path, key),
+					Field:   strings.Join(append(append([]string{}, path...), key), "."),
+					Message: fmt.Sprintf("key '%s' is defined more than once (lines %d and %d)", key, firstLine, line),
+					Line:    line,
+					Column:  column,
+				})
+			} else {
+				seen[key] = value
+			}
+			violations = append(violations, findDuplicateKeys(value.Value, append(path, key))...)
+		}
+	case *ast.MappingValueNode:
+		violations = append(violations, findDuplicateKeys(&ast.MappingNode{Values: []*ast.MappingValueNode{n}}, path)...)
+	case *ast.SequenceNode:
+		for _, value := range n.Values {
+			violations = append(violations, findDuplicateKeys(value, path)...)
+		}
+	case *ast.AnchorNode:
+		violations = append(violations, findDuplicateKeys(n.Value, path)...)
+	case *ast.TagNode:
+		violations = append(violations, findDuplicateKeys(n.Value, path)...)
+	}
+
+	return violations
+}
+
+// serviceForPath returns the service owning a key path, if any
+func serviceForPath(path []string, key string) string {
+	if len(path) == 0 || path[0] != "services" {
+		return ""
+	}
+	if len(path) == 1 {
+		return key
+	}
+	return path[1]
+}
+
+// validateDuplicates checks that environment variables, labels and volume
+// targets of a service are not declared more than once
+func validateDuplicates(serviceName string, service parser.Service, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+
+	if cfg.Duplicates.Environment {
+		violations = append(violations, findDuplicateEntries(serviceName, service, "environment", extractEnvKey, "environment variable")...)
+	}
+	if cfg.Duplicates.Labels {
+		violations = append(violations, findDuplicateEntries(serviceName, service, "labels", extractLabelKey, "label")...)
+	}
+	if cfg.Duplicates.VolumeTargets {
+		violations = append(violations, findDuplicateEntries(serviceName, service, "volumes", extractVolumeTarget, "volume target")...)
+	}
+
+	return violations
+}
+
+// findDuplicateEntries reports list entries of field sharing the same key
+func findDuplicateEntries(serviceName string, service parser.Service, field string, keyExtractor func(interface{}) string, what string) []Violation {
+	violations := make([]Violation, 0)
+
+	items, ok := service.Config[field].([]interface{})
+	if !ok || len(items) < 2 {
+		return violations
+	}
+
+	entries := sequenceEntries(service, field)
+	entryLine := func(i int) (int, int) {
+		if node := entryNode(entries, i); node != nil {
+			return parser.Position(node)
+		}
+		return service.Line, service.Column
+	}
+
+	seen := make(map[string]int, len(items))
+	for i, item := range items {
+		key := keyExtractor(item)
+		if key == "" {
+			continue
+		}
+		if first, ok := seen[key]; ok {
+			firstLine, _ := entryLine(first)
+			line, column := entryLine(i)
+			violations = append(violations, Violation{
+				Type:    "duplicate",
+				Service: serviceName,
+				Field:   field,
+				Message: fmt.Sprintf("%s '%s' is declared more than once (lines %d and %d)", what, key, firstLine, line),
+				Actual:  key,
+				Line:    line,
+				Column:  column,
+			})
+			continue
+		}
+		seen[key] = i
+	}
+
+	return violations
+}
+
+// extractVolumeTarget extracts the container path from a volume entry
+func extractVolumeTarget(item interface{}) string {
+	switch v := item.(type) {
+	case string:
+		// Format: "[source:]target[:mode]"
+		parts := strings.Split(v, ":")
+		if len(parts) == 1 {
+			return parts[0]
+		}
+		return parts[1]
+	case map[string]interface{}:
+		if target, ok := v["target"].(string); ok {
+			return target
+		}
+	}
+	return ""
+}
@@ -0,0 +1,168 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidate_DuplicateMappingKeys(t *testing.T) {
+	yaml := `
+services:
+  web:
+    image: nginx:latest
+    restart: always
+    image: nginx:alpine
+  web:
+    image: httpd:latest
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Duplicate keys should not fail parsing: %v", err)
+	}
+
+	result, err := Validate(file, config.NewDefaultConfig())
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	duplicates := violationsOfType(result.Violations, "duplicate")
+	if len(duplicates) != 2 {
+		t.Fatalf("Expected 2 duplicate violations, got %d: %v", len(duplicates), duplicates)
+	}
+
+	for _, v := range duplicates {
+		if v.Service != "web" {
+			t.Errorf("Expected violation attributed to 'web', got '%s'", v.Service)
+		}
+	}
+
+	if duplicates[0].Field != "services.web.image" || duplicates[0].Line != 6 {
+		t.Errorf("Expected duplicate 'services.web.image' on line 6, got '%s' on line %d", duplicates[0].Field, duplicates[0].Line)
+	}
+	if !strings.Contains(duplicates[0].Message, "lines 4 and 6") {
+		t.Errorf("Expected both positions in message, got '%s'", duplicates[0].Message)
+	}
+}
+
+func TestValidate_DuplicateEnvironmentMapKeys(t *testing.T) {
+	yaml := `
+services:
+  web:
+    environment:
+      FOO: "1"
+      FOO: "2"
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	result, err := Validate(file, config.NewDefaultConfig())
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	duplicates := violationsOfType(result.Violations, "duplicate")
+	if len(duplicates) != 1 || duplicates[0].Field != "services.web.environment.FOO" {
+		t.Errorf("Expected 1 duplicate on 'services.web.environment.FOO', got %v", duplicates)
+	}
+}
+
+func TestValidate_DuplicateListEntries(t *testing.T) {
+	yaml := `
+services:
+  web:
+    environment:
+      - FOO=1
+      - BAR=2
+      - FOO=2
+    volumes:
+      - ./data:/data
+      - /srv/data:/data:ro
+      - type: bind
+        source: ./logs
+        target: /logs
+      - ./other-logs:/logs
+    labels:
+      - "traefik.enable=true"
+      - "traefik.enable=false"
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	service := file.GetServices()["web"]
+
+	violations := validateDuplicates("web", service, cfg)
+	if len(violations) != 4 {
+		t.Fatalf("Expected 4 duplicate violations, got %d: %v", len(violations), violations)
+	}
+
+	expected := []struct {
+		field string
+		key   string
+		line  int
+	}{
+		{"environment", "FOO", 7},
+		{"labels", "traefik.enable", 17},
+		{"volumes", "/data", 10},
+		{"volumes", "/logs", 14},
+	}
+
+	for _, e := range expected {
+		found := false
+		for _, v := range violations {
+			if v.Field == e.field && v.Actual == e.key && v.Line == e.line {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected duplicate %s '%s' on line %d, got %v", e.field, e.key, e.line, violations)
+		}
+	}
+}
+
+func TestValidate_DuplicatesDisabled(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Duplicates.Environment = false
+
+	service := createService(
+		"web",
+		[]string{"environment"},
+		map[string]interface{}{
+			"environment": []interface{}{"FOO=1", "FOO=2"},
+		},
+	)
+
+	if violations := validateDuplicates("web", service, cfg); len(violations) != 0 {
+		t.Errorf("Expected no violations when disabled, got %v", violations)
+	}
+}
+
+func TestExtractVolumeTarget(t *testing.T) {
+	tests := []struct {
+		input    interface{}
+		expected string
+	}{
+		{"/data", "/data"},
+		{"./data:/app/data", "/app/data"},
+		{"named:/app/data:ro", "/app/data"},
+		{map[string]interface{}{"type": "volume", "target": "/cache"}, "/cache"},
+		{uint64(1), ""},
+	}
+
+	for _, tt := range tests {
+		if result := extractVolumeTarget(tt.input); result != tt.expected {
+			t.Errorf("extractVolumeTarget(%v) = '%s', expected '%s'", tt.input, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// validateFormat checks the layout of the raw file: indentation, sequence
+// indentation, trailing whitespace, blank lines between services and the
+// final newline
+func validateFormat(file *parser.ComposeFile, cfg *config.Config) ([]Violation, error) {
+	violations := make([]Violation, 0)
+	rules := cfg.Format
+	if !rules.Enabled {
+		return violations, nil
+	}
+	if err := checkFormatRules(rules); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range file.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		if values, ok := parser.MappingValues(doc.Body); ok {
+			violations = append(violations, checkIndentation(values, nil, rules)...)
+		}
+		if rules.BlankLinesBetweenServices >= 0 {
+			violations = append(violations, checkServiceSpacing(file.RawData, doc.Body, rules.BlankLinesBetweenServices)...)
+		}
+	}
+
+	lines := bytes.Split(file.RawData, []byte("\n"))
+	if rules.TrailingWhitespace {
+		blockLines := file.BlockScalarLines()
+		for i, line := range lines {
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			trimmed := bytes.TrimRight(line, " \t")
+			if len(trimmed) == len(line) || blockLines[i+1] {
+				continue
+			}
+			violations = append(violations, Violation{
+				Type:    "format",
+				Message: "trailing whitespace",
+				Line:    i + 1,
+				Column:  len(trimmed) + 1,
+			})
+		}
+	}
+
+	if rules.FinalNewline && len(file.RawData) > 0 {
+		data := file.RawData
+		switch {
+		case !bytes.HasSuffix(data, []byte("\n")):
+			violations = append(violations, Violation{
+				Type:    "format",
+				Message: "file does not end with a newline",
+				Line:    len(lines),
+				Column:  len(lines[len(lines)-1]) + 1,
+			})
+		case len(bytes.TrimRight(data, " \t\r\n")) > 0 && bytes.HasSuffix(bytes.TrimRight(data, " \t\r"), []byte("\n\n")):
+			content := bytes.TrimRight(data, " \t\r\n")
+			violations = append(violations, Violation{
+				Type:    "format",
+				Message: "file ends with blank lines",
+				Line:    bytes.Count(content, []byte("\n")) + 2,
+				Column:  1,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// checkFormatRules validates the format settings
+func checkFormatRules(rules config.FormatRules) error {
+	if rules.Indent < 1 {
+		return fmt.Errorf("invalid format indent: %d (expected at least 1)", rules.Indent)
+	}
+	switch rules.SequenceIndent {
+	case "", "indented", "compact":
+		return nil
+	}
+	return fmt.Errorf("invalid sequence indent: %q (expected indented or compact)", rules.SequenceIndent)
+}
+
+// checkIndentation compares the indentation of nested block mappings and
+// sequences with their parent key
+func checkIndentation(values []*ast.MappingValueNode, path []string, rules config.FormatRules) []Violation {
+	violations := make([]Violation, 0)
+
+	for _, value := range values {
+		key := value.Key.String()
+		keyPath := append(append([]string{}, path...), key)
+		_, keyColumn := parser.Position(value.Key)
+
+		switch node := parser.UnwrapNode(value.Value).(type) {
+		case *ast.MappingNode, *ast.MappingValueNode:
+			children, ok := parser.MappingValues(node)
+			if !ok || len(children) == 0 {
+				continue
+			}
+			line, column := parser.Position(children[0].Key)
+			if column-keyColumn != rules.Indent {
+				violations = append(violations, indentationViolation(keyPath, key, rules.Indent, column-keyColumn, line, column))
+			}
+			violations = append(violations, checkIndentation(children, keyPath, rules)...)
+
+		case *ast.SequenceNode:
+			if node.IsFlowStyle {
+				continue
+			}
+			line, column := parser.Position(node)
+			expected := column - keyColumn
+			switch rules.SequenceIndent {
+			case "indented":
+				expected = rules.Indent
+			case "compact":
+				expected = 0
+			}
+			if column-keyColumn != expected {
+				violations = append(violations, indentationViolation(keyPath, key, expected, column-keyColumn, line, column))
+			}
+			for _, entry := range node.Values {
+				if children, ok := parser.MappingValues(parser.UnwrapNode(entry)); ok {
+					violations = append(violations, checkIndentation(children, keyPath, rules)...)
+				}
+			}
+		}
+	}
+
+	return violations
+}
+
+// indentationViolation reports a nested block indented by the wrong amount
+func indentationViolation(path []string, key string, expected, actual, line, column int) Violation {
+	service := ""
+	if len(path) >= 2 && path[0] == "services" {
+		service = path[1]
+	}
+	return Violation{
+		Type:     "format",
+		Service:  service,
+		Field:    strings.Join(path, "."),
+		Message:  fmt.Sprintf("content of '%s' is indented by %d spaces, expected %d", key, actual, expected),
+		Expected: fmt.Sprint(expected),
+		Actual:   fmt.Sprint(actual),
+		Line:     line,
+		Column:   column,
+	}
+}
+
+// checkServiceSpacing counts the blank lines before every service but the
+// first. Comment lines directly above a service belong to it.
+func checkServiceSpacing(data []byte, body ast.Node, expected int) []Violation {
+	violations := make([]Violation, 0)
+	services, ok := parser.MappingValues(parser.UnwrapNode(childNode(body, "services")))
+	if !ok {
+		return violations
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	for i, service := range services {
+		if i == 0 {
+			continue
+		}
+		line, column := parser.Position(service.Key)
+		blank := 0
+		for n := line - 1; n >= 1 && n <= len(lines); n-- {
+			trimmed := bytes.TrimSpace(lines[n-1])
+			if len(trimmed) == 0 {
+				blank++
+				continue
+			}
+			if trimmed[0] != '#' {
+				break
+			}
+		}
+		if blank != expected {
+			violations = append(violations, Violation{
+				Type:     "format",
+				Service:  service.Key.String(),
+				Message:  fmt.Sprintf("expected %d blank lines before service, found %d", expected, blank),
+				Expected: fmt.Sprint(expected),
+				Actual:   fmt.Sprint(blank),
+				Line:     line,
+				Column:   column,
+			})
+		}
+	}
+
+	return violations
+}
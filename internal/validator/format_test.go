@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidateFormat(t *testing.T) {
+	yaml := "services:\n" +
+		"    web:\n" +
+		"      image: nginx   \n" +
+		"      ports:\n" +
+		"      - \"80:80\"\n" +
+		"      command: |\n" +
+		"        echo hi   \n" +
+		"    api:\n" +
+		"        image: myapp\n" +
+		"\n" +
+		"\n"
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	violations, err := validateFormat(file, cfg)
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("Expected no violations while disabled, got %+v (%v)", violations, err)
+	}
+
+	cfg.Format.Enabled = true
+	violations, err = validateFormat(file, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		service string
+		line    int
+	}{
+		{"", 2},    // services content indented by 4
+		{"web", 5}, // compact sequence
+		{"api", 9}, // api content indented by 4
+		{"api", 8}, // no blank line before api
+		{"", 3},    // trailing whitespace; the block scalar is exempt
+		{"", 10},   // extra blank lines at the end
+	}
+
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d violations, got %d: %+v", len(expected), len(violations), violations)
+	}
+	for i, e := range expected {
+		v := violations[i]
+		if v.Type != "format" || v.Service != e.service || v.Line != e.line {
+			t.Errorf("Violation %d: expected %s at line %d, got %+v", i, e.service, e.line, v)
+		}
+	}
+}
+
+func TestValidateFormat_MissingFinalNewline(t *testing.T) {
+	file, err := parser.ParseBytes("test.yml", []byte("services:\n  web:\n    image: nginx"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Format.Enabled = true
+	violations, err := validateFormat(file, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Line != 3 {
+		t.Errorf("Expected a final newline violation on line 3, got %+v", violations)
+	}
+}
+
+func TestValidateFormat_InvalidSettings(t *testing.T) {
+	file, err := parser.ParseBytes("test.yml", []byte("services: {}\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Format.Enabled = true
+	cfg.Format.SequenceIndent = "hanging"
+	if _, err := validateFormat(file, cfg); err == nil {
+		t.Error("Expected an error for an invalid sequence indent")
+	}
+
+	cfg.Format.SequenceIndent = "compact"
+	cfg.Format.Indent = 0
+	if _, err := validateFormat(file, cfg); err == nil {
+		t.Error("Expected an error for an invalid indent")
+	}
+}
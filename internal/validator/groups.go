@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// validateFieldGroups checks that the field groups of each service appear in
+// the configured order and, if enabled, are separated by blank lines
+func validateFieldGroups(file *parser.ComposeFile, services map[string]parser.Service, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+	lines := bytes.Split(file.RawData, []byte("\n"))
+
+	for _, service := range servicesInOrder(services) {
+		serviceCfg := cfg.ForService(service.Ref())
+		groups := serviceCfg.FieldGroups
+		if len(groups) == 0 {
+			continue
+		}
+		fieldOrder := serviceCfg.FieldOrder
+
+		latest := -1
+		reported := make(map[int]bool)
+		previous := -1
+		for _, field := range service.FieldOrder {
+			group := config.GroupAt(groups, config.FieldPosition(fieldOrder, field))
+			if group < 0 {
+				continue
+			}
+			line, column := fieldKeyPosition(service, field)
+
+			if group < latest && !reported[group] {
+				reported[group] = true
+				violations = append(violations, Violation{
+					Type:     "order",
+					Service:  service.Name,
+					Field:    field,
+					Message:  fmt.Sprintf("group '%s' must come before group '%s'", groups[group].Name, groups[latest].Name),
+					Expected: groups[group].Name,
+					Actual:   groups[latest].Name,
+					Line:     line,
+					Column:   column,
+				})
+			}
+			if group > latest {
+				latest = group
+			}
+
+			if cfg.GroupSeparators && previous >= 0 && group != previous && service.Node != nil && !blankLineBefore(lines, line) {
+				violations = append(violations, Violation{
+					Type:    "format",
+					Service: service.Name,
+					Field:   field,
+					Message: fmt.Sprintf("expected a blank line before group '%s'", groups[group].Name),
+					Line:    line,
+					Column:  column,
+				})
+			}
+			previous = group
+		}
+	}
+
+	return violations
+}
+
+// blankLineBefore checks if a blank line precedes a 1-based line, looking
+// past the comment lines directly above it
+func blankLineBefore(lines [][]byte, line int) bool {
+	for n := line - 1; n >= 1 && n <= len(lines); n-- {
+		trimmed := bytes.TrimSpace(lines[n-1])
+		if len(trimmed) == 0 {
+			return true
+		}
+		if trimmed[0] != '#' {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidateFieldGroups(t *testing.T) {
+	yaml := `services:
+  web:
+    image: nginx
+    command: run
+    container_name: web
+
+    environment:
+      - KEY=value
+`
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.FieldGroups = []config.FieldGroup{
+		{Name: "identity", Fields: []string{"container_name", "image"}},
+		{Name: "runtime", Fields: []string{"command", "environment"}},
+	}
+	cfg.FieldOrder = []string{"container_name", "image", "command", "environment"}
+
+	violations := validateFieldGroups(file, file.GetServices(), cfg)
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d: %+v", len(violations), violations)
+	}
+	if v := violations[0]; v.Type != "order" || v.Field != "container_name" || v.Line != 5 {
+		t.Errorf("Unexpected violation: %+v", v)
+	}
+
+	cfg.GroupSeparators = true
+	violations = validateFieldGroups(file, file.GetServices(), cfg)
+	if len(violations) != 3 {
+		t.Fatalf("Expected 3 violations, got %d: %+v", len(violations), violations)
+	}
+	expected := []struct {
+		typ  string
+		line int
+	}{
+		{"format", 4}, // command starts the runtime group
+		{"order", 5},  // identity after runtime
+		{"format", 5}, // container_name starts the identity group again
+	}
+	for i, e := range expected {
+		if v := violations[i]; v.Type != e.typ || v.Line != e.line {
+			t.Errorf("Violation %d: expected %s at line %d, got %+v", i, e.typ, e.line, v)
+		}
+	}
+}
+
+func TestValidateFieldGroups_NoGroups(t *testing.T) {
+	yaml := `services:
+  web:
+    image: nginx
+    command: run
+`
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.GroupSeparators = true
+	if violations := validateFieldGroups(file, file.GetServices(), cfg); len(violations) != 0 {
+		t.Errorf("Expected no violations without groups, got %+v", violations)
+	}
+}
@@ -0,0 +1,144 @@
+package validator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// defaultRegistry is the registry of images without an explicit registry host
+const defaultRegistry = "docker.io"
+
+// imageReference is a parsed "[registry/]repository[:tag][@digest]" reference
+type imageReference struct {
+	Registry   string // registry host, "docker.io" if omitted
+	Repository string // repository path as written, without registry
+	Tag        string
+	Digest     string
+}
+
+// FullName returns the fully qualified repository name
+func (r imageReference) FullName() string {
+	repository := r.Repository
+	if r.Registry == defaultRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return r.Registry + "/" + repository
+}
+
+// parseImageReference splits an image reference into its parts
+func parseImageReference(image string) imageReference {
+	ref := imageReference{Registry: defaultRegistry}
+
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		ref.Digest = image[idx+1:]
+		image = image[:idx]
+	}
+
+	// A tag is a colon after the last slash; earlier colons belong to a registry port
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		ref.Tag = image[idx+1:]
+		image = image[:idx]
+	}
+
+	// The first component is a registry host if it looks like one
+	if idx := strings.Index(image, "/"); idx >= 0 {
+		first := image[:idx]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			ref.Registry = first
+			image = image[idx+1:]
+		}
+	}
+
+	ref.Repository = image
+	return ref
+}
+
+// validateImage checks a service's image reference against the image policy
+func validateImage(serviceName string, service parser.Service, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+
+	image, ok := service.Config["image"].(string)
+	if !ok || image == "" || strings.Contains(image, "$") {
+		// Build-only services and interpolated references cannot be checked
+		return violations
+	}
+
+	line, column := service.Line, service.Column
+	if node := service.Field("image"); node != nil {
+		line, column = parser.Position(node)
+	}
+
+	report := func(message string) {
+		violations = append(violations, Violation{
+			Type:    "image",
+			Service: serviceName,
+			Field:   "image",
+			Message: message,
+			Actual:  image,
+			Line:    line,
+			Column:  column,
+		})
+	}
+
+	rules := cfg.Images
+	ref := parseImageReference(image)
+
+	if ref.Tag == "" && ref.Digest == "" {
+		if rules.RequireTag {
+			report(fmt.Sprintf("image '%s' has no tag", image))
+		} else if rules.DisallowLatest {
+			report(fmt.Sprintf("image '%s' implicitly uses the 'latest' tag", image))
+		}
+	}
+
+	if rules.DisallowLatest && ref.Tag == "latest" {
+		report(fmt.Sprintf("image '%s' uses the 'latest' tag", image))
+	}
+
+	if rules.RequireDigest && ref.Digest == "" {
+		report(fmt.Sprintf("image '%s' is not pinned to a digest", image))
+	}
+
+	if len(rules.AllowedRegistries) > 0 && !containsString(rules.AllowedRegistries, ref.Registry) {
+		report(fmt.Sprintf("image '%s' uses registry '%s' which is not allowed", image, ref.Registry))
+	}
+
+	if len(rules.AllowedRepositories) > 0 && !matchesRepository(rules.AllowedRepositories, ref) {
+		report(fmt.Sprintf("image '%s' does not match any allowed repository", image))
+	}
+
+	return violations
+}
+
+// matchesRepository reports whether ref matches one of the glob patterns,
+// either as written or fully qualified
+func matchesRepository(patterns []string, ref imageReference) bool {
+	written := ref.Repository
+	if ref.Registry != defaultRegistry {
+		written = ref.Registry + "/" + ref.Repository
+	}
+
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, written); matched {
+			return true
+		}
+		if matched, _ := path.Match(pattern, ref.FullName()); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString checks if a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+)
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		image    string
+		expected imageReference
+		fullName string
+	}{
+		{"nginx", imageReference{Registry: "docker.io", Repository: "nginx"}, "docker.io/library/nginx"},
+		{"nginx:1.25", imageReference{Registry: "docker.io", Repository: "nginx", Tag: "1.25"}, "docker.io/library/nginx"},
+		{"bitnami/redis:7.2", imageReference{Registry: "docker.io", Repository: "bitnami/redis", Tag: "7.2"}, "docker.io/bitnami/redis"},
+		{"ghcr.io/org/app:v1@sha256:abc", imageReference{Registry: "ghcr.io", Repository: "org/app", Tag: "v1", Digest: "sha256:abc"}, "ghcr.io/org/app"},
+		{"localhost:5000/app", imageReference{Registry: "localhost:5000", Repository: "app"}, "localhost:5000/app"},
+		{"alpine@sha256:abc", imageReference{Registry: "docker.io", Repository: "alpine", Digest: "sha256:abc"}, "docker.io/library/alpine"},
+	}
+
+	for _, tt := range tests {
+		ref := parseImageReference(tt.image)
+		if ref != tt.expected {
+			t.Errorf("parseImageReference(%q) = %+v, expected %+v", tt.image, ref, tt.expected)
+		}
+		if ref.FullName() != tt.fullName {
+			t.Errorf("FullName(%q) = %q, expected %q", tt.image, ref.FullName(), tt.fullName)
+		}
+	}
+}
+
+func TestValidateImage_Policy(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    string
+		rules    config.ImageRules
+		expected int
+	}{
+		{"no rules", "nginx", config.ImageRules{}, 0},
+		{"missing tag", "nginx", config.ImageRules{RequireTag: true}, 1},
+		{"digest satisfies tag", "nginx@sha256:abc", config.ImageRules{RequireTag: true}, 0},
+		{"latest tag", "nginx:latest", config.ImageRules{DisallowLatest: true}, 1},
+		{"implicit latest", "nginx", config.ImageRules{DisallowLatest: true}, 1},
+		{"pinned tag", "nginx:1.25", config.ImageRules{DisallowLatest: true, RequireTag: true}, 0},
+		{"missing digest", "nginx:1.25", config.ImageRules{RequireDigest: true}, 1},
+		{"allowed registry", "ghcr.io/org/app:v1", config.ImageRules{AllowedRegistries: []string{"ghcr.io"}}, 0},
+		{"docker hub not allowed", "nginx:1.25", config.ImageRules{AllowedRegistries: []string{"ghcr.io"}}, 1},
+		{"allowed repository", "ghcr.io/org/app:v1", config.ImageRules{AllowedRepositories: []string{"ghcr.io/org/*"}}, 0},
+		{"official image pattern", "postgres:16", config.ImageRules{AllowedRepositories: []string{"docker.io/library/*"}}, 0},
+		{"repository not allowed", "ghcr.io/other/app:v1", config.ImageRules{AllowedRepositories: []string{"ghcr.io/org/*"}}, 1},
+		{"interpolated image", "${IMAGE}", config.ImageRules{RequireTag: true, RequireDigest: true}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Images = tt.rules
+
+			service := createService("web", []string{"image"}, map[string]interface{}{"image": tt.image})
+			violations := validateImage("web", service, cfg)
+
+			if len(violations) != tt.expected {
+				t.Errorf("Expected %d violations for %q, got %d: %v", tt.expected, tt.image, len(violations), violations)
+			}
+			for _, v := range violations {
+				if v.Type != "image" || v.Field != "image" {
+					t.Errorf("Unexpected violation %+v", v)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateImage_BuildOnly(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Images.RequireTag = true
+
+	service := createService("web", []string{"build"}, map[string]interface{}{"build": "."})
+	if violations := validateImage("web", service, cfg); len(violations) != 0 {
+		t.Errorf("Expected no violations for build-only service, got %v", violations)
+	}
+}
@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// validateFieldPolicy checks that a service defines all required fields and
+// none of the forbidden ones
+func validateFieldPolicy(serviceName string, service parser.Service, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+
+	image, _ := service.Config["image"].(string)
+	required, forbidden := cfg.GetFieldPolicy(serviceName, image)
+
+	for _, field := range required {
+		if !hasFieldPath(service.Config, field) {
+			violations = append(violations, Violation{
+				Type:     "required_field",
+				Service:  serviceName,
+				Field:    field,
+				Message:  fmt.Sprintf("required field '%s' is missing", field),
+				Expected: field,
+				Line:     service.Line,
+				Column:   service.Column,
+			})
+		}
+	}
+
+	for _, field := range forbidden {
+		if hasFieldPath(service.Config, field) {
+			line, column := service.Line, service.Column
+			if node := service.Field(field); node != nil {
+				line, column = parser.Position(node)
+			}
+			violations = append(violations, Violation{
+				Type:    "forbidden_field",
+				Service: serviceName,
+				Field:   field,
+				Message: fmt.Sprintf("field '%s' is not allowed", field),
+				Actual:  field,
+				Line:    line,
+				Column:  column,
+			})
+		}
+	}
+
+	return violations
+}
+
+// hasFieldPath checks if a dotted path such as "deploy.resources.limits" or
+// "labels.com.example.team" exists in value. Keys may themselves contain dots,
+// so every split point is tried. List entries such as "KEY=value" or
+// "NET_ADMIN" match on their key.
+func hasFieldPath(value interface{}, path string) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := v[path]; ok {
+			return true
+		}
+		for i := 0; i < len(path); i++ {
+			if path[i] != '.' {
+				continue
+			}
+			if child, ok := v[path[:i]]; ok && hasFieldPath(child, path[i+1:]) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if key := extractEnvKey(item); key != "" && key == path {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidateFieldPolicy(t *testing.T) {
+	yaml := `
+services:
+  web:
+    image: nginx:1.25
+    restart: always
+    privileged: true
+    labels:
+      com.example.team: web
+  worker-email:
+    image: myapp:1.0
+    environment:
+      - QUEUE=email
+    deploy:
+      resources:
+        limits:
+          memory: 256M
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	services := file.GetServices()
+
+	cfg := config.NewDefaultConfig()
+	cfg.RequiredFields = []string{"restart", "labels.com.example.team"}
+	cfg.ForbiddenFields = []string{"privileged"}
+	cfg.FieldPolicies = []config.FieldPolicy{
+		{
+			Services:       []string{"worker-*"},
+			RequiredFields: []string{"deploy.resources.limits", "environment.QUEUE"},
+		},
+	}
+
+	web := validateFieldPolicy("web", services["web"], cfg)
+	if len(web) != 1 || web[0].Type != "forbidden_field" || web[0].Line != 6 {
+		t.Errorf("Expected forbidden 'privileged' on line 6 for web, got %v", web)
+	}
+
+	worker := validateFieldPolicy("worker-email", services["worker-email"], cfg)
+	if len(worker) != 2 {
+		t.Fatalf("Expected 2 violations for worker, got %d: %v", len(worker), worker)
+	}
+	for _, v := range worker {
+		if v.Type != "required_field" {
+			t.Errorf("Expected required_field violation, got '%s'", v.Type)
+		}
+		if v.Line != 9 {
+			t.Errorf("Expected violation at service line 9, got %d", v.Line)
+		}
+	}
+}
+
+func TestHasFieldPath(t *testing.T) {
+	cfg := map[string]interface{}{
+		"restart": "always",
+		"deploy": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"limits": map[string]interface{}{"cpus": "0.5"},
+			},
+		},
+		"labels":  []interface{}{"com.example.team=web"},
+		"cap_add": []interface{}{"NET_ADMIN"},
+	}
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"restart", true},
+		{"healthcheck", false},
+		{"deploy.resources.limits", true},
+		{"deploy.resources.reservations", false},
+		{"labels.com.example.team", true},
+		{"labels.com.example.owner", false},
+		{"cap_add.NET_ADMIN", true},
+	}
+
+	for _, tt := range tests {
+		if result := hasFieldPath(cfg, tt.path); result != tt.expected {
+			t.Errorf("hasFieldPath(%q) = %v, expected %v", tt.path, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// portBinding is a host port range published by a single ports entry
+type portBinding struct {
+	HostIP    string
+	StartPort int
+	EndPort   int
+	Protocol  string
+}
+
+// portOwner ties a binding to the service and file that publishes it
+type portOwner struct {
+	binding portBinding
+	file    string
+	service string
+	line    int
+	column  int
+}
+
+// validatePortConflicts checks that no two bindings in a file share a host port
+func validatePortConflicts(file string, services map[string]parser.Service) []Violation {
+	owners := collectPortOwners(file, services)
+
+	violations := make([]Violation, 0)
+	for i, owner := range owners {
+		for _, previous := range owners[:i] {
+			if port, ok := bindingsOverlap(previous.binding, owner.binding); ok {
+				violations = append(violations, portConflictViolation(owner, previous, port, false))
+				break
+			}
+		}
+	}
+
+	return violations
+}
+
+// validatePortConflictsAcrossFiles reports bindings colliding with a binding
+// of another service in another file. Conflicts within a single file are left
+// to Validate, and a service repeated in an override file does not conflict
+// with itself.
+func validatePortConflictsAcrossFiles(files []*parser.ComposeFile, resultsByFile map[string]*ValidationResult) {
+	owners := make([]portOwner, 0)
+	for _, file := range files {
+		owners = append(owners, collectPortOwners(file.Path, file.GetServices())...)
+	}
+
+	for i, owner := range owners {
+		for _, previous := range owners[:i] {
+			if previous.file == owner.file || previous.service == owner.service {
+				continue
+			}
+			if port, ok := bindingsOverlap(previous.binding, owner.binding); ok {
+				result := resultsByFile[owner.file]
+				result.Violations = append(result.Violations, portConflictViolation(owner, previous, port, true))
+				break
+			}
+		}
+	}
+}
+
+// collectPortOwners returns the host port bindings of all services in source order
+func collectPortOwners(file string, services map[string]parser.Service) []portOwner {
+	owners := make([]portOwner, 0)
+	for _, service := range servicesInOrder(services) {
+		ports, ok := service.Config["ports"].([]interface{})
+		if !ok {
+			continue
+		}
+
+		entries := sequenceEntries(service, "ports")
+		for i, entry := range ports {
+			line, column := service.Line, service.Column
+			if node := entryNode(entries, i); node != nil {
+				line, column = parser.Position(node)
+			}
+
+			for _, binding := range parsePortEntry(entry) {
+				owners = append(owners, portOwner{
+					binding: binding,
+					file:    file,
+					service: service.Name,
+					line:    line,
+					column:  column,
+				})
+			}
+		}
+	}
+
+	return owners
+}
+
+// portConflictViolation describes owner colliding with an earlier binding
+func portConflictViolation(owner, previous portOwner, port int, otherFile bool) Violation {
+	where := fmt.Sprintf("line %d", previous.line)
+	if otherFile {
+		where = fmt.Sprintf("%s:%d", previous.file, previous.line)
+	}
+
+	hostPort := fmt.Sprintf("%d/%s", port, owner.binding.Protocol)
+	if owner.binding.HostIP != "" {
+		hostPort = owner.binding.HostIP + ":" + hostPort
+	}
+
+	return Violation{
+		Type:    "port",
+		Service: owner.service,
+		Field:   "ports",
+		Message: fmt.Sprintf("host port %s is already bound by service '%s' (%s)", hostPort, previous.service, where),
+		Actual:  hostPort,
+		Line:    owner.line,
+		Column:  owner.column,
+	}
+}
+
+// bindingsOverlap reports whether two bindings claim a common host port,
+// returning the first port they share
+func bindingsOverlap(a, b portBinding) (int, bool) {
+	if a.Protocol != b.Protocol {
+		return 0, false
+	}
+	if !hostIPsOverlap(a.HostIP, b.HostIP) {
+		return 0, false
+	}
+	if a.StartPort > b.EndPort || b.StartPort > a.EndPort {
+		return 0, false
+	}
+	if a.StartPort > b.StartPort {
+		return a.StartPort, true
+	}
+	return b.StartPort, true
+}
+
+// hostIPsOverlap treats an unset or unspecified address as binding every interface
+func hostIPsOverlap(a, b string) bool {
+	if isWildcardIP(a) || isWildcardIP(b) {
+		return true
+	}
+	return a == b
+}
+
+func isWildcardIP(ip string) bool {
+	return ip == "" || ip == "0.0.0.0" || ip == "::"
+}
+
+// parsePortEntry parses a short or long syntax ports entry into the host
+// bindings it publishes. Entries without a fixed host port publish nothing.
+func parsePortEntry(entry interface{}) []portBinding {
+	switch v := entry.(type) {
+	case string:
+		if binding, ok := parseShortPort(v); ok {
+			return []portBinding{binding}
+		}
+	case map[string]interface{}:
+		if binding, ok := parseLongPort(v); ok {
+			return []portBinding{binding}
+		}
+	}
+	return nil
+}
+
+// parseShortPort parses "[HOST_IP:][HOST_PORT:]CONTAINER_PORT[/PROTOCOL]"
+func parseShortPort(spec string) (portBinding, bool) {
+	binding := portBinding{Protocol: "tcp"}
+
+	if idx := strings.LastIndex(spec, "/"); idx >= 0 {
+		binding.Protocol = strings.ToLower(spec[idx+1:])
+		spec = spec[:idx]
+	}
+
+	// Bracketed IPv6 host address, e.g. "[::1]:8080:80"
+	if strings.HasPrefix(spec, "[") {
+		end := strings.Index(spec, "]")
+		if end < 0 || len(spec) <= end+1 || spec[end+1] != ':' {
+			return binding, false
+		}
+		binding.HostIP = spec[1:end]
+		spec = spec[end+2:]
+		parts := strings.Split(spec, ":")
+		if len(parts) != 2 {
+			return binding, false
+		}
+		return withHostPorts(binding, parts[0])
+	}
+
+	parts := strings.Split(spec, ":")
+	switch len(parts) {
+	case 2:
+		return withHostPorts(binding, parts[0])
+	case 3:
+		binding.HostIP = parts[0]
+		return withHostPorts(binding, parts[1])
+	}
+
+	// Container port only: the host port is chosen by the engine
+	return binding, false
+}
+
+// parseLongPort parses the long syntax {target, published, host_ip, protocol}
+func parseLongPort(spec map[string]interface{}) (portBinding, bool) {
+	binding := portBinding{Protocol: "tcp"}
+
+	if protocol, ok := spec["protocol"].(string); ok && protocol != "" {
+		binding.Protocol = strings.ToLower(protocol)
+	}
+	if hostIP, ok := spec["host_ip"].(string); ok {
+		binding.HostIP = hostIP
+	}
+
+	published, ok := spec["published"]
+	if !ok {
+		return binding, false
+	}
+	return withHostPorts(binding, fmt.Sprint(published))
+}
+
+// withHostPorts sets the host port range of binding from "PORT" or "START-END"
+func withHostPorts(binding portBinding, ports string) (portBinding, bool) {
+	if ports == "" {
+		return binding, false
+	}
+
+	start, end := ports, ports
+	if idx := strings.Index(ports, "-"); idx >= 0 {
+		start, end = ports[:idx], ports[idx+1:]
+	}
+
+	startPort, err := strconv.Atoi(start)
+	if err != nil {
+		return binding, false
+	}
+	endPort, err := strconv.Atoi(end)
+	if err != nil || endPort < startPort {
+		return binding, false
+	}
+
+	binding.StartPort = startPort
+	binding.EndPort = endPort
+	return binding, true
+}
@@ -0,0 +1,248 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestParsePortEntry_ShortSyntax(t *testing.T) {
+	tests := []struct {
+		entry    interface{}
+		expected []portBinding
+	}{
+		{"8080:80", []portBinding{{StartPort: 8080, EndPort: 8080, Protocol: "tcp"}}},
+		{"127.0.0.1:8080:80", []portBinding{{HostIP: "127.0.0.1", StartPort: 8080, EndPort: 8080, Protocol: "tcp"}}},
+		{"53:53/udp", []portBinding{{StartPort: 53, EndPort: 53, Protocol: "udp"}}},
+		{"9000-9005:9000-9005", []portBinding{{StartPort: 9000, EndPort: 9005, Protocol: "tcp"}}},
+		{"[::1]:6000:6000", []portBinding{{HostIP: "::1", StartPort: 6000, EndPort: 6000, Protocol: "tcp"}}},
+		{"3000", nil},
+		{"127.0.0.1::5000", nil},
+		{"${PORT}:80", nil},
+		{uint64(80), nil},
+	}
+
+	for _, tt := range tests {
+		bindings := parsePortEntry(tt.entry)
+		if len(bindings) != len(tt.expected) {
+			t.Errorf("parsePortEntry(%v): expected %d bindings, got %d: %v", tt.entry, len(tt.expected), len(bindings), bindings)
+			continue
+		}
+		for i := range bindings {
+			if bindings[i] != tt.expected[i] {
+				t.Errorf("parsePortEntry(%v) = %+v, expected %+v", tt.entry, bindings[i], tt.expected[i])
+			}
+		}
+	}
+}
+
+func TestParsePortEntry_LongSyntax(t *testing.T) {
+	entry := map[string]interface{}{
+		"target":    uint64(80),
+		"published": "8080",
+		"host_ip":   "10.0.0.1",
+		"protocol":  "UDP",
+	}
+
+	bindings := parsePortEntry(entry)
+	if len(bindings) != 1 {
+		t.Fatalf("Expected 1 binding, got %d", len(bindings))
+	}
+
+	expected := portBinding{HostIP: "10.0.0.1", StartPort: 8080, EndPort: 8080, Protocol: "udp"}
+	if bindings[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, bindings[0])
+	}
+
+	if bindings := parsePortEntry(map[string]interface{}{"target": uint64(80)}); len(bindings) != 0 {
+		t.Errorf("Expected no binding without published port, got %v", bindings)
+	}
+}
+
+func TestBindingsOverlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     portBinding
+		expected bool
+	}{
+		{"same port", portBinding{StartPort: 80, EndPort: 80, Protocol: "tcp"}, portBinding{StartPort: 80, EndPort: 80, Protocol: "tcp"}, true},
+		{"different protocol", portBinding{StartPort: 53, EndPort: 53, Protocol: "tcp"}, portBinding{StartPort: 53, EndPort: 53, Protocol: "udp"}, false},
+		{"different host IPs", portBinding{HostIP: "10.0.0.1", StartPort: 80, EndPort: 80, Protocol: "tcp"}, portBinding{HostIP: "10.0.0.2", StartPort: 80, EndPort: 80, Protocol: "tcp"}, false},
+		{"wildcard IP", portBinding{HostIP: "0.0.0.0", StartPort: 80, EndPort: 80, Protocol: "tcp"}, portBinding{HostIP: "10.0.0.2", StartPort: 80, EndPort: 80, Protocol: "tcp"}, true},
+		{"range overlap", portBinding{StartPort: 9000, EndPort: 9005, Protocol: "tcp"}, portBinding{StartPort: 9005, EndPort: 9010, Protocol: "tcp"}, true},
+		{"disjoint ranges", portBinding{StartPort: 9000, EndPort: 9004, Protocol: "tcp"}, portBinding{StartPort: 9005, EndPort: 9010, Protocol: "tcp"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := bindingsOverlap(tt.a, tt.b); ok != tt.expected {
+				t.Errorf("Expected overlap=%v, got %v", tt.expected, ok)
+			}
+		})
+	}
+}
+
+func TestValidate_PortConflict(t *testing.T) {
+	yaml := `
+services:
+  web:
+    image: nginx:latest
+    ports:
+      - "8080:80"
+  api:
+    image: myapp:latest
+    ports:
+      - "9000:9000"
+      - "8080:8000"
+  dns:
+    image: coredns:latest
+    ports:
+      - "8080:53/udp"
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	result, err := Validate(file, config.NewDefaultConfig())
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	conflicts := violationsOfType(result.Violations, "port")
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected 1 port conflict, got %d: %v", len(conflicts), conflicts)
+	}
+
+	if conflicts[0].Service != "api" {
+		t.Errorf("Expected conflict reported on 'api', got '%s'", conflicts[0].Service)
+	}
+	if conflicts[0].Line != 11 {
+		t.Errorf("Expected conflict on line 11, got %d", conflicts[0].Line)
+	}
+}
+
+func TestValidate_PortConflictDisabled(t *testing.T) {
+	yaml := `
+services:
+  web:
+    ports:
+      - "8080:80"
+  api:
+    ports:
+      - "8080:80"
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Ports.CheckConflicts = false
+
+	result, err := Validate(file, cfg)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if conflicts := violationsOfType(result.Violations, "port"); len(conflicts) != 0 {
+		t.Errorf("Expected no port conflicts when disabled, got %v", conflicts)
+	}
+}
+
+func TestValidateAcrossFiles_PortConflict(t *testing.T) {
+	first, err := parser.ParseBytes("first.yml", []byte(`
+services:
+  web:
+    ports:
+      - "8080:80"
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	second, err := parser.ParseBytes("second.yml", []byte(`
+services:
+  proxy:
+    ports:
+      - target: 80
+        published: 8080
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	files := []*parser.ComposeFile{first, second}
+
+	for _, result := range ValidateAcrossFiles(files, []*config.Config{cfg, cfg}) {
+		if !result.Valid {
+			t.Errorf("Expected no cross-file checks by default, got %v", result.Violations)
+		}
+	}
+
+	cfg.Ports.AcrossFiles = true
+	results := ValidateAcrossFiles(files, []*config.Config{cfg, cfg})
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if !results[0].Valid {
+		t.Errorf("Expected first file to be valid, got %v", results[0].Violations)
+	}
+	if len(results[1].Violations) != 1 || results[1].Violations[0].Service != "proxy" {
+		t.Errorf("Expected 1 conflict on 'proxy' in second file, got %v", results[1].Violations)
+	}
+
+	// Files whose own configuration leaves the rule off are not compared
+	for _, result := range ValidateAcrossFiles(files, []*config.Config{cfg, config.NewDefaultConfig()}) {
+		if !result.Valid {
+			t.Errorf("Expected no conflict with a file not opting in, got %v", result.Violations)
+		}
+	}
+}
+
+func TestValidateAcrossFiles_PortOverrideFile(t *testing.T) {
+	base, err := parser.ParseBytes("docker-compose.yml", []byte(`
+services:
+  web:
+    image: nginx
+    ports:
+      - "80:80"
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	override, err := parser.ParseBytes("docker-compose.override.yml", []byte(`
+services:
+  web:
+    ports:
+      - "80:80"
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Ports.AcrossFiles = true
+
+	// An override file repeating a service's ports does not conflict with it
+	for _, result := range ValidateAcrossFiles([]*parser.ComposeFile{base, override}, []*config.Config{cfg, cfg}) {
+		if !result.Valid {
+			t.Errorf("Expected no conflict for the same service, got %v", result.Violations)
+		}
+	}
+}
+
+func violationsOfType(violations []Violation, violationType string) []Violation {
+	matching := make([]Violation, 0)
+	for _, v := range violations {
+		if v.Type == violationType {
+			matching = append(matching, v)
+		}
+	}
+	return matching
+}
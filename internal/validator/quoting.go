@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/goccy/go-yaml/token"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// validateQuoting checks quoting of port mappings, label values and
+// version-like values, and the quote style of every quoted scalar
+func validateQuoting(file *parser.ComposeFile, cfg *config.Config) ([]Violation, error) {
+	violations := make([]Violation, 0)
+	rules := cfg.Style
+
+	switch rules.QuoteStyle {
+	case "", "double", "single":
+	default:
+		return nil, fmt.Errorf("invalid quote style: %q (expected double or single)", rules.QuoteStyle)
+	}
+
+	for _, doc := range file.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+		parser.WalkScalars(doc.Body, nil, false, func(node ast.Node, path []string, isKey bool) {
+			tk := node.GetToken()
+			line, column := parser.Position(node)
+			service := ""
+			if len(path) >= 2 && path[0] == "services" {
+				service = path[1]
+			}
+
+			if quoted := quoteStyle(tk); quoted != "" {
+				if rules.QuoteStyle != "" && quoted != rules.QuoteStyle {
+					violations = append(violations, Violation{
+						Type:     "quoting",
+						Service:  service,
+						Field:    strings.Join(path, "."),
+						Message:  fmt.Sprintf("use %s quotes instead of %s quotes", rules.QuoteStyle, quoted),
+						Expected: rules.QuoteStyle,
+						Actual:   quoted,
+						Line:     line,
+						Column:   column,
+					})
+				}
+				return
+			}
+
+			if isKey {
+				return
+			}
+			if reason := unquotedReason(tk, path, rules); reason != "" {
+				violations = append(violations, Violation{
+					Type:    "quoting",
+					Service: service,
+					Field:   strings.Join(path, "."),
+					Message: fmt.Sprintf("%s '%s' should be quoted", reason, tk.Value),
+					Actual:  tk.Value,
+					Line:    line,
+					Column:  column,
+				})
+			}
+		})
+	}
+
+	return violations, nil
+}
+
+// unquotedReason describes why a plain scalar must be quoted, or returns an
+// empty string if it may stay plain
+func unquotedReason(tk *token.Token, path []string, rules config.StyleRules) string {
+	inService := len(path) == 4 && path[0] == "services"
+	switch {
+	case rules.QuotePorts && inService && path[2] == "ports":
+		return "port mapping"
+	case rules.QuoteLabelValues && inService && path[2] == "labels":
+		return "label value"
+	case rules.QuoteVersions && parser.IsVersionLike(tk.Value):
+		return "version-like value"
+	}
+	return ""
+}
+
+// quoteStyle returns "double" or "single" for quoted tokens
+func quoteStyle(tk *token.Token) string {
+	switch tk.Type {
+	case token.DoubleQuoteType:
+		return "double"
+	case token.SingleQuoteType:
+		return "single"
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidateQuoting(t *testing.T) {
+	yaml := `version: 3.8
+services:
+  web:
+    image: 'nginx:1.25'
+    ports:
+      - 80:80
+      - "443:443"
+      - target: 8080
+    labels:
+      com.example.enabled: true
+      com.example.team: "web"
+  api:
+    image: "myapp:1.0"
+    labels:
+      - com.example.enabled=yes
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	violations, err := validateQuoting(file, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(violations) != 0 {
+		t.Errorf("Expected no violations by default, got %+v", violations)
+	}
+
+	cfg.Style.QuotePorts = true
+	cfg.Style.QuoteLabelValues = true
+	cfg.Style.QuoteVersions = true
+	cfg.Style.QuoteStyle = "double"
+	violations, err = validateQuoting(file, cfg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		field string
+		line  int
+	}{
+		{"version", 1},
+		{"services.web.image", 4},
+		{"services.web.ports.0", 6},
+		{"services.web.labels.com.example.enabled", 10},
+		{"services.api.labels.0", 15},
+	}
+
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d violations, got %d: %+v", len(expected), len(violations), violations)
+	}
+	for i, e := range expected {
+		v := violations[i]
+		if v.Type != "quoting" || v.Field != e.field || v.Line != e.line {
+			t.Errorf("Violation %d: expected %s at line %d, got %+v", i, e.field, e.line, v)
+		}
+	}
+}
+
+func TestValidateQuoting_InvalidStyle(t *testing.T) {
+	file, err := parser.ParseBytes("test.yml", []byte("services: {}\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Style.QuoteStyle = "backtick"
+	if _, err := validateQuoting(file, cfg); err == nil {
+		t.Error("Expected an error for an invalid quote style")
+	}
+}
@@ -0,0 +1,318 @@
+package validator
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-yaml"
+	"github.com/goccy/go-yaml/ast"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// composeSpecSchema is the Compose Specification JSON schema, taken from
+// github.com/compose-spec/compose-go (Apache License 2.0)
+//
+//go:embed schema/compose-spec.json
+var composeSpecSchema []byte
+
+var (
+	composeSchemaOnce sync.Once
+	composeSchema     *jsonschema.Schema
+	composeSchemaErr  error
+)
+
+var (
+	typeErrorPattern       = regexp.MustCompile(`^expected (.+), but got (.+)$`)
+	additionalPropsPattern = regexp.MustCompile(`'([^']*)'`)
+)
+
+// loadComposeSchema compiles the embedded schema once
+func loadComposeSchema() (*jsonschema.Schema, error) {
+	composeSchemaOnce.Do(func() {
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource("compose-spec.json", bytes.NewReader(composeSpecSchema)); err != nil {
+			composeSchemaErr = fmt.Errorf("failed to load compose schema: %w", err)
+			return
+		}
+		composeSchema, composeSchemaErr = compiler.Compile("compose-spec.json")
+		if composeSchemaErr != nil {
+			composeSchemaErr = fmt.Errorf("failed to compile compose schema: %w", composeSchemaErr)
+		}
+	})
+	return composeSchema, composeSchemaErr
+}
+
+// validateSchema validates every document against the Compose Specification
+func validateSchema(file *parser.ComposeFile) ([]Violation, error) {
+	schema, err := loadComposeSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]Violation, 0)
+	for _, doc := range file.Documents {
+		if doc == nil || doc.Body == nil {
+			continue
+		}
+
+		var content interface{}
+		if err := yaml.NodeToValue(doc.Body, &content, yaml.AllowDuplicateMapKey()); err != nil {
+			return nil, fmt.Errorf("failed to decode document in %s: %w", file.Path, err)
+		}
+
+		err := schema.Validate(toJSONValue(content))
+		if err == nil {
+			continue
+		}
+		validationErr, ok := err.(*jsonschema.ValidationError)
+		if !ok {
+			return nil, err
+		}
+
+		for _, leaf := range schemaErrorLeaves(validationErr) {
+			violations = append(violations, schemaViolations(doc.Body, leaf)...)
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Line < violations[j].Line
+	})
+
+	return violations, nil
+}
+
+// schemaError is a single reportable schema failure
+type schemaError struct {
+	InstanceLocation string
+	Message          string
+	Keyword          string
+}
+
+// schemaErrorLeaves flattens the error tree into reportable failures. For
+// oneOf/anyOf, branches that failed deeper than their type check explain the
+// problem best; if every branch failed on type, the types are combined.
+func schemaErrorLeaves(err *jsonschema.ValidationError) []schemaError {
+	if len(err.Causes) == 0 {
+		return []schemaError{{
+			InstanceLocation: err.InstanceLocation,
+			Message:          err.Message,
+			Keyword:          lastPointerToken(err.KeywordLocation),
+		}}
+	}
+
+	keyword := lastPointerToken(err.KeywordLocation)
+	if keyword == "oneOf" || keyword == "anyOf" {
+		deeper := make([]*jsonschema.ValidationError, 0)
+		expected := make([]string, 0)
+		got := ""
+		for _, cause := range err.Causes {
+			match := typeErrorPattern.FindStringSubmatch(cause.Message)
+			if len(cause.Causes) == 0 && match != nil && cause.InstanceLocation == err.InstanceLocation {
+				expected = appendUniqueString(expected, match[1])
+				got = match[2]
+				continue
+			}
+			deeper = append(deeper, cause)
+		}
+
+		if len(deeper) == 0 && len(expected) > 0 {
+			return []schemaError{{
+				InstanceLocation: err.InstanceLocation,
+				Message:          fmt.Sprintf("expected %s, but got %s", strings.Join(expected, " or "), got),
+				Keyword:          "type",
+			}}
+		}
+		if len(deeper) > 0 {
+			leaves := make([]schemaError, 0)
+			for _, cause := range deeper {
+				leaves = append(leaves, schemaErrorLeaves(cause)...)
+			}
+			return leaves
+		}
+	}
+
+	leaves := make([]schemaError, 0)
+	for _, cause := range err.Causes {
+		leaves = append(leaves, schemaErrorLeaves(cause)...)
+	}
+	return leaves
+}
+
+// schemaViolations converts a schema failure into violations positioned on
+// the offending AST node
+func schemaViolations(root ast.Node, err schemaError) []Violation {
+	tokens := pointerTokens(err.InstanceLocation)
+	service := ""
+	if len(tokens) >= 2 && tokens[0] == "services" {
+		service = tokens[1]
+	}
+
+	node := nodeAtPointer(root, tokens)
+
+	if err.Keyword == "additionalProperties" {
+		violations := make([]Violation, 0)
+		for _, match := range additionalPropsPattern.FindAllStringSubmatch(err.Message, -1) {
+			name := match[1]
+			keyTokens := append(append([]string{}, tokens...), name)
+			line, column := parser.Position(keyNodeAtPointer(root, keyTokens))
+			if line == 0 {
+				line, column = parser.Position(node)
+			}
+			if len(tokens) == 1 && tokens[0] == "services" {
+				service = name
+			}
+			violations = append(violations, Violation{
+				Type:    "schema",
+				Service: service,
+				Field:   strings.Join(keyTokens, "."),
+				Message: fmt.Sprintf("field '%s' is not allowed by the Compose Specification", name),
+				Actual:  name,
+				Line:    line,
+				Column:  column,
+			})
+		}
+		return violations
+	}
+
+	line, column := parser.Position(node)
+	field := strings.Join(tokens, ".")
+	message := err.Message
+	if field != "" {
+		message = fmt.Sprintf("'%s': %s", field, err.Message)
+	}
+
+	return []Violation{{
+		Type:    "schema",
+		Service: service,
+		Field:   field,
+		Message: message,
+		Line:    line,
+		Column:  column,
+	}}
+}
+
+// nodeAtPointer follows JSON pointer tokens through the AST and returns the
+// deepest node reached
+func nodeAtPointer(node ast.Node, tokens []string) ast.Node {
+	for _, token := range tokens {
+		next := childNode(node, token)
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
+
+// keyNodeAtPointer returns the key node of the mapping entry named by the
+// last token, or nil
+func keyNodeAtPointer(root ast.Node, tokens []string) ast.Node {
+	if len(tokens) == 0 {
+		return nil
+	}
+	parent := root
+	for _, token := range tokens[:len(tokens)-1] {
+		parent = childNode(parent, token)
+		if parent == nil {
+			return nil
+		}
+	}
+	if mapping, ok := parser.UnwrapNode(parent).(*ast.MappingNode); ok {
+		for _, value := range mapping.Values {
+			if value.Key.String() == tokens[len(tokens)-1] {
+				return value.Key
+			}
+		}
+	}
+	return nil
+}
+
+// childNode returns the mapping value or sequence entry named by token
+func childNode(node ast.Node, token string) ast.Node {
+	switch n := parser.UnwrapNode(node).(type) {
+	case *ast.MappingNode:
+		for _, value := range n.Values {
+			if value.Key.String() == token {
+				return value.Value
+			}
+		}
+	case *ast.MappingValueNode:
+		if n.Key.String() == token {
+			return n.Value
+		}
+	case *ast.SequenceNode:
+		if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(n.Values) {
+			return n.Values[i]
+		}
+	}
+	return nil
+}
+
+// pointerTokens splits a JSON pointer into unescaped tokens
+func pointerTokens(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// lastPointerToken returns the final token of a JSON pointer
+func lastPointerToken(pointer string) string {
+	tokens := pointerTokens(pointer)
+	if len(tokens) == 0 {
+		return ""
+	}
+	return tokens[len(tokens)-1]
+}
+
+// toJSONValue converts decoded YAML into the value types the schema
+// validator understands
+func toJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[key] = toJSONValue(item)
+		}
+		return converted
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = toJSONValue(item)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = toJSONValue(item)
+		}
+		return converted
+	case nil, bool, string, int, int8, int32, int64, uint, uint8, uint32, uint64, float32, float64:
+		return v
+	case int16:
+		return int64(v)
+	case uint16:
+		return uint64(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// appendUniqueString appends value if not already present
+func appendUniqueString(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
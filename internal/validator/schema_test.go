@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func schemaViolationsFor(t *testing.T, yaml string) []Violation {
+	t.Helper()
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	violations, err := validateSchema(file)
+	if err != nil {
+		t.Fatalf("validateSchema failed: %v", err)
+	}
+	return violations
+}
+
+func TestValidateSchema_Valid(t *testing.T) {
+	violations := schemaViolationsFor(t, `
+version: '3.8'
+x-common: &common
+  restart: always
+services:
+  web:
+    <<: *common
+    image: nginx:latest
+    environment:
+      KEY: value
+    ports:
+      - "8080:80"
+      - target: 443
+        published: 8443
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost"]
+      interval: 30s
+`)
+
+	if len(violations) != 0 {
+		t.Errorf("Expected no schema violations, got %v", violations)
+	}
+}
+
+func TestValidateSchema_UnknownField(t *testing.T) {
+	violations := schemaViolationsFor(t, `
+services:
+  web:
+    image: nginx:latest
+    enviroment:
+      - KEY=value
+`)
+
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 schema violation, got %d: %v", len(violations), violations)
+	}
+
+	v := violations[0]
+	if v.Service != "web" || v.Actual != "enviroment" || v.Line != 5 || v.Column != 5 {
+		t.Errorf("Expected 'enviroment' on web at 5:5, got %+v", v)
+	}
+}
+
+func TestValidateSchema_WrongTypes(t *testing.T) {
+	violations := schemaViolationsFor(t, `
+services:
+  web:
+    image: nginx:latest
+    privileged: "yes"
+    environment: 5
+    ports:
+      - "8080:80"
+      - target: 80
+        published: 8080
+        unknown: true
+`)
+
+	expected := []struct {
+		line    int
+		message string
+	}{
+		{5, "'services.web.privileged': expected boolean, but got string"},
+		{6, "'services.web.environment': expected object or array, but got number"},
+		{11, "field 'unknown' is not allowed"},
+	}
+
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d schema violations, got %d: %v", len(expected), len(violations), violations)
+	}
+
+	for i, e := range expected {
+		if violations[i].Line != e.line || !strings.Contains(violations[i].Message, e.message) {
+			t.Errorf("Violation %d: expected '%s' on line %d, got '%s' on line %d",
+				i, e.message, e.line, violations[i].Message, violations[i].Line)
+		}
+	}
+}
+
+func TestValidate_SchemaDisabled(t *testing.T) {
+	file, err := parser.ParseBytes("test.yml", []byte("services:\n  web:\n    restat: always\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.SchemaValidation = false
+
+	result, err := Validate(file, cfg)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if schema := violationsOfType(result.Violations, "schema"); len(schema) != 0 {
+		t.Errorf("Expected no schema violations when disabled, got %v", schema)
+	}
+}
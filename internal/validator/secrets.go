@@ -0,0 +1,187 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// credentialPatterns match well-known credential formats
+var credentialPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"private key", regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`)},
+}
+
+// keyedValue is an environment or label entry with its key and value
+type keyedValue struct {
+	Key      string
+	Value    string
+	HasValue bool
+	Node     ast.Node
+}
+
+// validateSecrets scans environment and label values for plaintext secrets
+func validateSecrets(serviceName string, service parser.Service, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+	rules := cfg.Secrets
+	if !rules.Enabled {
+		return violations
+	}
+
+	fields := []struct {
+		name string
+		what string
+	}{
+		{"environment", "environment variable"},
+		{"labels", "label"},
+	}
+
+	for _, field := range fields {
+		for _, entry := range keyedValues(service, field.name) {
+			if !entry.HasValue || entry.Value == "" || matchesKeyPattern(rules.Allow, entry.Key) {
+				continue
+			}
+
+			reason := secretReason(entry, rules)
+			if reason == "" {
+				continue
+			}
+
+			line, column := service.Line, service.Column
+			if entry.Node != nil {
+				line, column = parser.Position(entry.Node)
+			}
+
+			violations = append(violations, Violation{
+				Type:    "secret",
+				Service: serviceName,
+				Field:   field.name,
+				Message: fmt.Sprintf("%s '%s' %s; use secrets: or ${VAR} interpolation instead", field.what, entry.Key, reason),
+				Actual:  entry.Key,
+				Line:    line,
+				Column:  column,
+			})
+		}
+	}
+
+	return violations
+}
+
+// secretReason explains why an entry looks like a plaintext secret, or
+// returns an empty string if it does not
+func secretReason(entry keyedValue, rules config.SecretRules) string {
+	for _, credential := range credentialPatterns {
+		if credential.pattern.MatchString(entry.Value) {
+			return fmt.Sprintf("contains what looks like a %s", credential.name)
+		}
+	}
+
+	// Interpolated values are resolved from the environment at deploy time
+	if strings.Contains(entry.Value, "$") {
+		return ""
+	}
+
+	if matchesKeyPattern(rules.SensitiveKeys, entry.Key) {
+		return "has a literal value"
+	}
+
+	if rules.MinLength > 0 && len(entry.Value) >= rules.MinLength &&
+		!strings.ContainsAny(entry.Value, " \t") &&
+		shannonEntropy(entry.Value) >= rules.EntropyThreshold {
+		return "has a high-entropy value"
+	}
+
+	return ""
+}
+
+// matchesKeyPattern checks a key against case-insensitive glob patterns
+func matchesKeyPattern(patterns []string, key string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(strings.ToUpper(pattern), strings.ToUpper(key)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// keyedValues returns the entries of an environment or labels field in
+// either list ("KEY=value") or map (KEY: value) form
+func keyedValues(service parser.Service, field string) []keyedValue {
+	values := make([]keyedValue, 0)
+
+	switch v := service.Config[field].(type) {
+	case []interface{}:
+		entries := sequenceEntries(service, field)
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				continue
+			}
+			entry := keyedValue{Key: s, Node: entryNode(entries, i)}
+			if idx := strings.Index(s, "="); idx > 0 {
+				entry.Key = s[:idx]
+				entry.Value = s[idx+1:]
+				entry.HasValue = true
+			}
+			values = append(values, entry)
+		}
+
+	case map[string]interface{}:
+		var nodes map[string]ast.Node
+		if mapping, ok := service.Field(field).(*ast.MappingNode); ok {
+			nodes = make(map[string]ast.Node, len(mapping.Values))
+			for _, value := range mapping.Values {
+				nodes[value.Key.String()] = value.Value
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			entry := keyedValue{Key: key, Node: nodes[key]}
+			if v[key] != nil {
+				entry.Value = fmt.Sprint(v[key])
+				entry.HasValue = true
+			}
+			values = append(values, entry)
+		}
+	}
+
+	return values
+}
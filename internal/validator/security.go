@@ -0,0 +1,158 @@
+package validator
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/goccy/go-yaml/ast"
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// dockerSocketPaths are host paths of the Docker daemon socket
+var dockerSocketPaths = []string{"/var/run/docker.sock", "/run/docker.sock"}
+
+// volumeMount is a parsed volumes entry
+type volumeMount struct {
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// validateSecurity runs the security rule pack against a service
+func validateSecurity(serviceName string, service parser.Service, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+	rules := cfg.Security
+	if !rules.Enabled {
+		return violations
+	}
+
+	applies := func(check config.SecurityCheck) bool {
+		return check.Enabled && !check.IsAllowed(serviceName)
+	}
+	report := func(rule, field string, node ast.Node, message string) {
+		line, column := service.Line, service.Column
+		if node != nil {
+			line, column = parser.Position(node)
+		}
+		violations = append(violations, Violation{
+			Type:     "security",
+			Service:  serviceName,
+			Field:    field,
+			Message:  message,
+			Expected: rule,
+			Line:     line,
+			Column:   column,
+		})
+	}
+
+	if applies(rules.Privileged) {
+		if privileged, ok := service.Config["privileged"].(bool); ok && privileged {
+			report("privileged", "privileged", service.Field("privileged"), "service runs in privileged mode")
+		}
+	}
+
+	if applies(rules.CapAdd) {
+		caps, _ := service.Config["cap_add"].([]interface{})
+		entries := sequenceEntries(service, "cap_add")
+		for i, c := range caps {
+			capability := normalizeCapability(fmt.Sprint(c))
+			for _, dangerous := range rules.DangerousCapabilities {
+				if capability == normalizeCapability(dangerous) {
+					report("cap_add", "cap_add", entryNode(entries, i), fmt.Sprintf("service adds dangerous capability '%s'", capability))
+					break
+				}
+			}
+		}
+	}
+
+	if applies(rules.NetworkModeHost) {
+		if mode, ok := service.Config["network_mode"].(string); ok && mode == "host" {
+			report("network_mode_host", "network_mode", service.Field("network_mode"), "service uses the host network namespace")
+		}
+	}
+
+	if applies(rules.PIDHost) {
+		if pid, ok := service.Config["pid"].(string); ok && pid == "host" {
+			report("pid_host", "pid", service.Field("pid"), "service uses the host PID namespace")
+		}
+	}
+
+	volumes, _ := service.Config["volumes"].([]interface{})
+	volumeEntries := sequenceEntries(service, "volumes")
+	for i, v := range volumes {
+		mount, ok := parseVolumeMount(v)
+		if !ok || !isHostPath(mount.Source) {
+			continue
+		}
+		source := path.Clean(mount.Source)
+
+		if applies(rules.DockerSocket) && containsString(dockerSocketPaths, source) {
+			report("docker_socket", "volumes", entryNode(volumeEntries, i), fmt.Sprintf("service mounts the Docker socket '%s'", mount.Source))
+			continue
+		}
+
+		if applies(rules.SensitiveMounts) && !mount.ReadOnly && containsString(rules.SensitivePaths, source) {
+			report("sensitive_mounts", "volumes", entryNode(volumeEntries, i), fmt.Sprintf("service mounts sensitive host path '%s' writable", mount.Source))
+		}
+	}
+
+	if applies(rules.SecurityOpt) {
+		opts, _ := service.Config["security_opt"].([]interface{})
+		entries := sequenceEntries(service, "security_opt")
+		for i, o := range opts {
+			opt := strings.ReplaceAll(strings.ToLower(fmt.Sprint(o)), "=", ":")
+			if opt == "seccomp:unconfined" || opt == "apparmor:unconfined" {
+				report("security_opt", "security_opt", entryNode(entries, i), fmt.Sprintf("security_opt '%v' disables a security profile", o))
+			}
+		}
+	}
+
+	if applies(rules.MissingUser) {
+		if _, ok := service.Config["user"]; !ok {
+			report("missing_user", "user", nil, "service does not set 'user' and may run as root")
+		}
+	}
+
+	return violations
+}
+
+// normalizeCapability upper-cases a capability and strips the CAP_ prefix
+func normalizeCapability(capability string) string {
+	return strings.TrimPrefix(strings.ToUpper(capability), "CAP_")
+}
+
+// parseVolumeMount parses a short "source:target[:mode]" or long syntax entry
+func parseVolumeMount(item interface{}) (volumeMount, bool) {
+	switch v := item.(type) {
+	case string:
+		parts := strings.Split(v, ":")
+		if len(parts) < 2 {
+			// Anonymous volume with no source
+			return volumeMount{Target: parts[0]}, true
+		}
+		mount := volumeMount{Source: parts[0], Target: parts[1]}
+		if len(parts) > 2 {
+			for _, option := range strings.Split(parts[2], ",") {
+				if option == "ro" {
+					mount.ReadOnly = true
+				}
+			}
+		}
+		return mount, true
+	case map[string]interface{}:
+		mount := volumeMount{}
+		mount.Source, _ = v["source"].(string)
+		mount.Target, _ = v["target"].(string)
+		mount.ReadOnly, _ = v["read_only"].(bool)
+		return mount, true
+	}
+	return volumeMount{}, false
+}
+
+// isHostPath reports whether a volume source is a bind-mounted host path
+// rather than a named volume
+func isHostPath(source string) bool {
+	return strings.HasPrefix(source, "/") || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "~")
+}
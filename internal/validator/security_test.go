@@ -0,0 +1,116 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+const riskyCompose = `
+services:
+  admin:
+    image: admin:1.0
+    network_mode: host
+    pid: host
+    cap_add:
+      - NET_BIND_SERVICE
+      - CAP_SYS_ADMIN
+    privileged: true
+    security_opt:
+      - seccomp=unconfined
+      - no-new-privileges:true
+    volumes:
+      - /var/run/docker.sock:/var/run/docker.sock:ro
+      - /etc:/host/etc
+      - /proc:/host/proc:ro
+      - type: bind
+        source: /
+        target: /host
+      - data:/data
+`
+
+func securityViolations(t *testing.T, cfg *config.Config) []Violation {
+	t.Helper()
+
+	file, err := parser.ParseBytes("test.yml", []byte(riskyCompose))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	return validateSecurity("admin", file.GetServices()["admin"], cfg)
+}
+
+func TestValidateSecurity_DisabledByDefault(t *testing.T) {
+	if violations := securityViolations(t, config.NewDefaultConfig()); len(violations) != 0 {
+		t.Errorf("Expected security pack to be opt-in, got %v", violations)
+	}
+}
+
+func TestValidateSecurity_AllChecks(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Security.Enabled = true
+
+	violations := securityViolations(t, cfg)
+
+	expected := map[string]int{
+		"privileged":        10,
+		"cap_add":           9,
+		"network_mode_host": 5,
+		"pid_host":          6,
+		"docker_socket":     15,
+		"sensitive_mounts":  16,
+		"security_opt":      12,
+		"missing_user":      3,
+	}
+
+	found := make(map[string]int)
+	for _, v := range violations {
+		if v.Type != "security" {
+			t.Errorf("Unexpected violation type '%s'", v.Type)
+		}
+		if _, ok := found[v.Expected]; ok && v.Expected != "sensitive_mounts" {
+			t.Errorf("Rule '%s' reported more than once", v.Expected)
+		}
+		if _, ok := found[v.Expected]; !ok {
+			found[v.Expected] = v.Line
+		}
+	}
+
+	for rule, line := range expected {
+		if found[rule] != line {
+			t.Errorf("Expected rule '%s' on line %d, got line %d", rule, line, found[rule])
+		}
+	}
+
+	// /etc writable and / via long syntax; /proc is read-only
+	mounts := 0
+	for _, v := range violations {
+		if v.Expected == "sensitive_mounts" {
+			mounts++
+		}
+	}
+	if mounts != 2 {
+		t.Errorf("Expected 2 sensitive mount violations, got %d", mounts)
+	}
+}
+
+func TestValidateSecurity_PerRuleConfiguration(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Security.Enabled = true
+	cfg.Security.MissingUser.Enabled = false
+	cfg.Security.Privileged.Allow = []string{"adm*"}
+	cfg.Security.DangerousCapabilities = []string{"NET_BIND_SERVICE"}
+	cfg.Security.SensitivePaths = []string{}
+
+	for _, v := range securityViolations(t, cfg) {
+		switch v.Expected {
+		case "missing_user", "privileged", "sensitive_mounts":
+			t.Errorf("Rule '%s' should not be reported", v.Expected)
+		case "cap_add":
+			if v.Line != 8 {
+				t.Errorf("Expected only NET_BIND_SERVICE on line 8 to be reported, got line %d", v.Line)
+			}
+		}
+	}
+}
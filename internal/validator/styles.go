@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+// validateKeyValueStyles checks that environment and labels are written in
+// the configured list or map form
+func validateKeyValueStyles(services map[string]parser.Service, cfg *config.Config) ([]Violation, error) {
+	violations := make([]Violation, 0)
+	ordered := servicesInOrder(services)
+
+	fields := []struct {
+		name    string
+		setting string
+	}{
+		{"environment", cfg.Style.Environment},
+		{"labels", cfg.Style.Labels},
+	}
+
+	for _, field := range fields {
+		expected, err := expectedKeyValueStyle(field.name, field.setting, ordered)
+		if err != nil {
+			return nil, err
+		}
+		if expected == "" {
+			continue
+		}
+
+		for _, service := range ordered {
+			actual := keyValueStyle(service.Config[field.name])
+			if actual == "" || actual == expected {
+				continue
+			}
+			line, column := fieldKeyPosition(service, field.name)
+			violations = append(violations, Violation{
+				Type:     "style",
+				Service:  service.Name,
+				Field:    field.name,
+				Message:  fmt.Sprintf("%s uses %s form, expected %s form", field.name, actual, expected),
+				Expected: expected,
+				Actual:   actual,
+				Line:     line,
+				Column:   column,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// expectedKeyValueStyle resolves a style setting to "list", "map" or "" (any).
+// "consistent" takes the form of the first service in the file, so each file
+// is consistent on its own, as --fix converts one file at a time.
+func expectedKeyValueStyle(field, setting string, ordered []parser.Service) (string, error) {
+	switch setting {
+	case "", "list", "map":
+		return setting, nil
+	case "consistent":
+		for _, service := range ordered {
+			if style := keyValueStyle(service.Config[field]); style != "" {
+				return style, nil
+			}
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("invalid style for %s: %q (expected list, map or consistent)", field, setting)
+}
+
+// keyValueStyle returns the form of an environment or labels value
+func keyValueStyle(value interface{}) string {
+	switch value.(type) {
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestValidateKeyValueStyles(t *testing.T) {
+	yaml := `
+services:
+  web:
+    environment:
+      - KEY=value
+    labels:
+      com.example.team: web
+  api:
+    environment:
+      KEY: value
+    labels:
+      - com.example.team=api
+  db:
+    image: postgres:16
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	services := file.GetServices()
+
+	tests := []struct {
+		name        string
+		environment string
+		labels      string
+		expected    []string // service/field pairs
+	}{
+		{"disabled", "", "", nil},
+		{"list environment", "list", "", []string{"api/environment"}},
+		{"map environment", "map", "", []string{"web/environment"}},
+		{"consistent follows first service", "consistent", "consistent", []string{"api/environment", "api/labels"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.Style.Environment = tt.environment
+			cfg.Style.Labels = tt.labels
+
+			violations, err := validateKeyValueStyles(services, cfg)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(violations) != len(tt.expected) {
+				t.Fatalf("Expected %d violations, got %d: %+v", len(tt.expected), len(violations), violations)
+			}
+			for i, v := range violations {
+				if got := v.Service + "/" + v.Field; got != tt.expected[i] || v.Type != "style" {
+					t.Errorf("Violation %d: expected %s, got %+v", i, tt.expected[i], v)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateKeyValueStyles_InvalidSetting(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Style.Labels = "dict"
+
+	if _, err := validateKeyValueStyles(map[string]parser.Service{}, cfg); err == nil {
+		t.Error("Expected an error for an invalid style")
+	}
+}
@@ -0,0 +1,135 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+var (
+	knownServiceFieldsOnce sync.Once
+	knownServiceFields     []string
+)
+
+// composeServiceFields returns the service fields defined by the embedded
+// Compose Specification schema
+func composeServiceFields() []string {
+	knownServiceFieldsOnce.Do(func() {
+		var spec struct {
+			Definitions struct {
+				Service struct {
+					Properties map[string]json.RawMessage `json:"properties"`
+				} `json:"service"`
+			} `json:"definitions"`
+		}
+		if err := json.Unmarshal(composeSpecSchema, &spec); err != nil {
+			return
+		}
+		for field := range spec.Definitions.Service.Properties {
+			knownServiceFields = append(knownServiceFields, field)
+		}
+		sort.Strings(knownServiceFields)
+	})
+	return knownServiceFields
+}
+
+// validateTypos reports unknown service fields that are close to a known
+// Compose field or a field from the configured order
+func validateTypos(serviceName string, service parser.Service, fieldOrder []string, cfg *config.Config) []Violation {
+	violations := make([]Violation, 0)
+	if !cfg.Typos.Enabled {
+		return violations
+	}
+
+	known := append(append([]string{}, composeServiceFields()...), fieldOrder...)
+
+	for _, field := range service.FieldOrder {
+		if strings.HasPrefix(field, "x-") || containsString(known, field) {
+			continue
+		}
+
+		suggestion, ok := closestField(field, known, cfg.Typos.MaxDistance)
+		if !ok {
+			continue
+		}
+
+		line, column := fieldKeyPosition(service, field)
+
+		violations = append(violations, Violation{
+			Type:     "unknown_field",
+			Service:  serviceName,
+			Field:    field,
+			Message:  fmt.Sprintf("unknown field '%s', did you mean '%s'?", field, suggestion),
+			Expected: suggestion,
+			Actual:   field,
+			Line:     line,
+			Column:   column,
+		})
+	}
+
+	return violations
+}
+
+// closestField returns the candidate with the smallest edit distance to
+// field, if that distance is within maxDistance and shorter than the field
+func closestField(field string, candidates []string, maxDistance int) (string, bool) {
+	best := ""
+	bestDistance := maxDistance + 1
+	for _, candidate := range candidates {
+		distance := editDistance(field, candidate)
+		if distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	if best == "" || bestDistance > maxDistance || bestDistance >= len(field) {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance computes the optimal string alignment distance between a and
+// b: insertions, deletions, substitutions and adjacent transpositions
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// minInt returns the smallest of its arguments
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
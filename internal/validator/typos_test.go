@@ -0,0 +1,113 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/yourusername/compose-validator/internal/config"
+	"github.com/yourusername/compose-validator/internal/parser"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"restart", "restart", 0},
+		{"restat", "restart", 1},
+		{"enviroment", "environment", 1},
+		{"imgae", "image", 1},
+		{"labes", "labels", 1},
+		{"foo", "volumes", 6},
+		{"", "pid", 3},
+	}
+
+	for _, tt := range tests {
+		if d := editDistance(tt.a, tt.b); d != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tt.a, tt.b, d, tt.expected)
+		}
+	}
+}
+
+func TestValidateTypos(t *testing.T) {
+	yaml := `
+services:
+  web:
+    imgae: nginx:latest
+    restat: always
+    enviroment:
+      - KEY=value
+    x-custom: true
+    completely_unrelated: true
+    depends_on: [db]
+`
+
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	service := file.GetServices()["web"]
+	violations := validateTypos("web", service, cfg.FieldOrder, cfg)
+
+	expected := []struct {
+		actual     string
+		suggestion string
+		line       int
+	}{
+		{"imgae", "image", 4},
+		{"restat", "restart", 5},
+		{"enviroment", "environment", 6},
+	}
+
+	if len(violations) != len(expected) {
+		t.Fatalf("Expected %d typo violations, got %d: %v", len(expected), len(violations), violations)
+	}
+	for i, e := range expected {
+		v := violations[i]
+		if v.Actual != e.actual || v.Expected != e.suggestion || v.Line != e.line {
+			t.Errorf("Expected '%s' -> '%s' on line %d, got %+v", e.actual, e.suggestion, e.line, v)
+		}
+	}
+}
+
+func TestValidateTypos_ConfiguredFieldOrder(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.Typos.MaxDistance = 1
+
+	service := createService("web", []string{"team_owner", "restaaart"}, map[string]interface{}{
+		"team_owner": "platform",
+		"restaaart":  "always",
+	})
+
+	// Custom fields from field_order are known, and "restaaart" is too far off
+	violations := validateTypos("web", service, []string{"team_owner", "restart"}, cfg)
+	if len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+
+	service = createService("web", []string{"team_ownr"}, map[string]interface{}{"team_ownr": "platform"})
+	violations = validateTypos("web", service, []string{"team_owner"}, cfg)
+	if len(violations) != 1 || violations[0].Expected != "team_owner" {
+		t.Errorf("Expected suggestion 'team_owner', got %v", violations)
+	}
+}
+
+func TestValidate_TypoNotReportedTwice(t *testing.T) {
+	file, err := parser.ParseBytes("test.yml", []byte("services:\n  web:\n    image: nginx:1.25\n    restat: always\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	result, err := Validate(file, config.NewDefaultConfig())
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	if typos := violationsOfType(result.Violations, "unknown_field"); len(typos) != 1 {
+		t.Errorf("Expected 1 unknown_field violation, got %v", typos)
+	}
+	if schema := violationsOfType(result.Violations, "schema"); len(schema) != 0 {
+		t.Errorf("Expected schema violation to be covered by the typo rule, got %v", schema)
+	}
+}
@@ -2,15 +2,17 @@ package validator
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/goccy/go-yaml/ast"
 	"github.com/yourusername/compose-validator/internal/config"
 	"github.com/yourusername/compose-validator/internal/parser"
 )
 
 // Violation represents a validation error
 type Violation struct {
-	Type     string // "order" or "alphabetization"
+	Type     string // Rule that reported it, e.g. "order", "alphabetization", "port"
 	Service  string
 	Field    string
 	Message  string
@@ -18,6 +20,8 @@ type Violation struct {
 	Actual   string
 	Line     int
 	Column   int
+	// Severity is "error" or "warning", as configured for the rule
+	Severity string
 }
 
 // ValidationResult contains all violations found in a file
@@ -27,6 +31,17 @@ type ValidationResult struct {
 	Violations []Violation
 }
 
+// Errors returns the number of violations reported as errors
+func (r *ValidationResult) Errors() int {
+	count := 0
+	for _, v := range r.Violations {
+		if v.Severity != "warning" {
+			count++
+		}
+	}
+	return count
+}
+
 // Validate validates a Docker Compose file
 func Validate(file *parser.ComposeFile, cfg *config.Config) (*ValidationResult, error) {
 	result := &ValidationResult{
@@ -37,46 +52,201 @@ func Validate(file *parser.ComposeFile, cfg *config.Config) (*ValidationResult,
 
 	services := file.GetServices()
 
+	// Positions of misspelled fields, so the schema rule does not report them twice
+	typoPositions := make(map[[2]int]bool)
+
 	for serviceName, service := range services {
+		// Apply the service_overrides matching this service
+		serviceCfg := cfg.ForService(service.Ref())
+
 		// Get field order for this service
-		fieldOrder := cfg.GetFieldOrder(serviceName)
+		fieldOrder := serviceCfg.FieldOrder
 
 		// Validate field order
-		orderViolations := validateFieldOrder(serviceName, service, fieldOrder, cfg)
+		orderViolations := ValidateFieldOrder(serviceName, service, fieldOrder, serviceCfg)
 		result.Violations = append(result.Violations, orderViolations...)
 
 		// Validate alphabetization
-		alphaViolations := validateAlphabetization(serviceName, service, cfg)
+		alphaViolations := ValidateAlphabetization(serviceName, service, serviceCfg)
 		result.Violations = append(result.Violations, alphaViolations...)
+
+		// Validate duplicate list entries
+		dupViolations := validateDuplicates(serviceName, service, serviceCfg)
+		result.Violations = append(result.Violations, dupViolations...)
+
+		// Validate image reference policy
+		imageViolations := validateImage(serviceName, service, serviceCfg)
+		result.Violations = append(result.Violations, imageViolations...)
+
+		// Validate security hardening rule pack
+		securityViolations := validateSecurity(serviceName, service, serviceCfg)
+		result.Violations = append(result.Violations, securityViolations...)
+
+		// Validate required and forbidden fields
+		policyViolations := validateFieldPolicy(serviceName, service, serviceCfg)
+		result.Violations = append(result.Violations, policyViolations...)
+
+		// Validate environment and labels for plaintext secrets
+		secretViolations := validateSecrets(serviceName, service, serviceCfg)
+		result.Violations = append(result.Violations, secretViolations...)
+
+		// Validate unknown fields for likely typos
+		typoViolations := validateTypos(serviceName, service, fieldOrder, serviceCfg)
+		for _, v := range typoViolations {
+			typoPositions[[2]int{v.Line, v.Column}] = true
+		}
+		result.Violations = append(result.Violations, typoViolations...)
+	}
+
+	// Validate order and separation of field groups
+	groupViolations := validateFieldGroups(file, services, cfg)
+	result.Violations = append(result.Violations, groupViolations...)
+
+	// Validate duplicate mapping keys in the raw AST
+	if cfg.Duplicates.Keys {
+		keyViolations := validateDuplicateKeys(file)
+		result.Violations = append(result.Violations, keyViolations...)
 	}
 
-	if len(result.Violations) > 0 {
-		result.Valid = false
+	// Validate documents against the Compose Specification
+	if cfg.SchemaValidation {
+		schemaViolations, err := validateSchema(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range schemaViolations {
+			if !typoPositions[[2]int{v.Line, v.Column}] {
+				result.Violations = append(result.Violations, v)
+			}
+		}
 	}
 
+	// Validate container names
+	nameViolations, err := validateContainerNames(file, services, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result.Violations = append(result.Violations, nameViolations...)
+
+	// Validate list or map form of environment and labels
+	styleViolations, err := validateKeyValueStyles(services, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result.Violations = append(result.Violations, styleViolations...)
+
+	// Validate quoting of scalar values
+	quotingViolations, err := validateQuoting(file, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result.Violations = append(result.Violations, quotingViolations...)
+
+	// Validate layout of the raw file
+	formatViolations, err := validateFormat(file, cfg)
+	if err != nil {
+		return nil, err
+	}
+	result.Violations = append(result.Violations, formatViolations...)
+
+	// Validate obsolete Compose syntax
+	deprecationViolations := validateDeprecations(file, services, cfg)
+	result.Violations = append(result.Violations, deprecationViolations...)
+
+	// Validate host port bindings across services
+	if cfg.Ports.CheckConflicts {
+		portViolations := validatePortConflicts(file.Path, services)
+		result.Violations = append(result.Violations, portViolations...)
+	}
+
+	applySeverities(result, services, cfg)
+
 	return result, nil
 }
 
-// validateFieldOrder checks if fields are in the correct order
-func validateFieldOrder(serviceName string, service parser.Service, fieldOrder []string, cfg *config.Config) []Violation {
+// ValidateAcrossFiles checks rules that compare services from several files
+// checked in one run, returning one result per file in the given order.
+// configs holds the configuration of each file; a file takes part in a rule
+// when its configuration enables the rule across files.
+func ValidateAcrossFiles(files []*parser.ComposeFile, configs []*config.Config) []*ValidationResult {
+	results := make([]*ValidationResult, 0, len(files))
+	resultsByFile := make(map[string]*ValidationResult, len(files))
+	portFiles := make([]*parser.ComposeFile, 0)
+	nameFiles := make([]*parser.ComposeFile, 0)
+	for i, file := range files {
+		result := &ValidationResult{
+			File:       file.Path,
+			Valid:      true,
+			Violations: make([]Violation, 0),
+		}
+		results = append(results, result)
+		resultsByFile[file.Path] = result
+
+		cfg := configs[i]
+		if cfg.Ports.CheckConflicts && cfg.Ports.AcrossFiles {
+			portFiles = append(portFiles, file)
+		}
+		if cfg.ContainerNames.Unique && cfg.ContainerNames.AcrossFiles {
+			nameFiles = append(nameFiles, file)
+		}
+	}
+
+	validatePortConflictsAcrossFiles(portFiles, resultsByFile)
+	validateContainerNamesAcrossFiles(nameFiles, resultsByFile)
+
+	for i, result := range results {
+		applySeverities(result, files[i].GetServices(), configs[i])
+	}
+
+	return results
+}
+
+// applySeverities drops violations of rules turned off and marks the others
+// as errors or warnings, using the settings of the service they belong to. A
+// result holding only warnings stays valid.
+func applySeverities(result *ValidationResult, services map[string]parser.Service, cfg *config.Config) {
+	serviceConfigs := make(map[string]*config.Config)
+	kept := make([]Violation, 0, len(result.Violations))
+
+	for _, v := range result.Violations {
+		ruleCfg := cfg
+		if service, ok := services[v.Service]; ok {
+			if _, ok := serviceConfigs[v.Service]; !ok {
+				serviceConfigs[v.Service] = cfg.ForService(service.Ref())
+			}
+			ruleCfg = serviceConfigs[v.Service]
+		}
+
+		severity := ruleCfg.Severity(v.Type)
+		if severity == "off" {
+			continue
+		}
+		v.Severity = severity
+		if severity == "error" {
+			result.Valid = false
+		}
+		kept = append(kept, v)
+	}
+
+	result.Violations = kept
+}
+
+// ValidateFieldOrder checks if fields are in the correct order. Fields
+// matching a pattern or the "*" placeholder are checked at that position.
+func ValidateFieldOrder(serviceName string, service parser.Service, fieldOrder []string, cfg *config.Config) []Violation {
 	violations := make([]Violation, 0)
 
 	// Get actual fields in the order they appear in the YAML file
 	actualFields := make([]string, 0)
 	for _, field := range service.FieldOrder {
-		// Only check fields that are in our field order
-		if isInFieldOrder(field, fieldOrder) {
+		// Only check fields that have a position in our field order
+		if config.FieldPosition(fieldOrder, field) >= 0 {
 			actualFields = append(actualFields, field)
 		}
 	}
 
-	// Filter expected fields to only those present in the service
-	expectedFields := make([]string, 0)
-	for _, field := range fieldOrder {
-		if _, ok := service.Config[field]; ok {
-			expectedFields = append(expectedFields, field)
-		}
-	}
+	// Sort the same fields into the expected order
+	expectedFields := cfg.OrderFields(fieldOrder, actualFields)
 
 	// Check order
 	for i, actual := range actualFields {
@@ -97,7 +267,7 @@ func validateFieldOrder(serviceName string, service parser.Service, fieldOrder [
 	// Check for extra fields if strict mode
 	if cfg.Strict {
 		for _, field := range service.FieldOrder {
-			if !isInFieldOrder(field, fieldOrder) {
+			if !config.IsListedField(fieldOrder, field) {
 				violations = append(violations, Violation{
 					Type:    "order",
 					Service: serviceName,
@@ -111,14 +281,14 @@ func validateFieldOrder(serviceName string, service parser.Service, fieldOrder [
 	return violations
 }
 
-// validateAlphabetization checks if environment, volumes, and labels are alphabetized
-func validateAlphabetization(serviceName string, service parser.Service, cfg *config.Config) []Violation {
+// ValidateAlphabetization checks if environment, volumes, and labels are alphabetized
+func ValidateAlphabetization(serviceName string, service parser.Service, cfg *config.Config) []Violation {
 	violations := make([]Violation, 0)
 
 	// Check environment variables
 	if cfg.ShouldAlphabetize("environment") {
 		if env, ok := service.Config["environment"].([]interface{}); ok {
-			if !isAlphabetized(env, extractEnvKey) {
+			if !isAlphabetized(env, extractEnvKey, cfg.Comparison("environment")) {
 				violations = append(violations, Violation{
 					Type:    "alphabetization",
 					Service: serviceName,
@@ -127,9 +297,9 @@ func validateAlphabetization(serviceName string, service parser.Service, cfg *co
 					Line:    service.Line,
 				})
 			}
-		} else if envMap, ok := service.Config["environment"].(map[string]interface{}); ok {
-			// Environment can also be a map
-			if !isMapAlphabetized(envMap) {
+		} else if _, ok := service.Config["environment"].(map[string]interface{}); ok {
+			// Environment can also be a map, checked in file order
+			if !keysAlphabetized(service.Keys("environment"), cfg.Comparison("environment")) {
 				violations = append(violations, Violation{
 					Type:    "alphabetization",
 					Service: serviceName,
@@ -144,7 +314,7 @@ func validateAlphabetization(serviceName string, service parser.Service, cfg *co
 	// Check volumes
 	if cfg.ShouldAlphabetize("volumes") {
 		if vols, ok := service.Config["volumes"].([]interface{}); ok {
-			if !isAlphabetized(vols, extractVolumeKey) {
+			if !isAlphabetized(vols, extractVolumeKey, cfg.Comparison("volumes")) {
 				violations = append(violations, Violation{
 					Type:    "alphabetization",
 					Service: serviceName,
@@ -159,7 +329,7 @@ func validateAlphabetization(serviceName string, service parser.Service, cfg *co
 	// Check labels
 	if cfg.ShouldAlphabetize("labels") {
 		if labels, ok := service.Config["labels"].([]interface{}); ok {
-			if !isAlphabetized(labels, extractLabelKey) {
+			if !isAlphabetized(labels, extractLabelKey, cfg.Comparison("labels")) {
 				violations = append(violations, Violation{
 					Type:    "alphabetization",
 					Service: serviceName,
@@ -168,9 +338,9 @@ func validateAlphabetization(serviceName string, service parser.Service, cfg *co
 					Line:    service.Line,
 				})
 			}
-		} else if labelMap, ok := service.Config["labels"].(map[string]interface{}); ok {
-			// Labels can also be a map
-			if !isMapAlphabetized(labelMap) {
+		} else if _, ok := service.Config["labels"].(map[string]interface{}); ok {
+			// Labels can also be a map, checked in file order
+			if !keysAlphabetized(service.Keys("labels"), cfg.Comparison("labels")) {
 				violations = append(violations, Violation{
 					Type:    "alphabetization",
 					Service: serviceName,
@@ -185,75 +355,68 @@ func validateAlphabetization(serviceName string, service parser.Service, cfg *co
 	return violations
 }
 
-// isInFieldOrder checks if a field is in the field order list
-func isInFieldOrder(field string, fieldOrder []string) bool {
-	for _, f := range fieldOrder {
-		if f == field {
-			return true
-		}
+// servicesInOrder returns services sorted by their position in the file
+func servicesInOrder(services map[string]parser.Service) []parser.Service {
+	ordered := make([]parser.Service, 0, len(services))
+	for _, service := range services {
+		ordered = append(ordered, service)
 	}
-	return false
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].Line != ordered[j].Line {
+			return ordered[i].Line < ordered[j].Line
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+	return ordered
 }
 
-// isAlphabetized checks if a slice is alphabetized by the given key extractor
-func isAlphabetized(items []interface{}, keyExtractor func(interface{}) string) bool {
-	if len(items) < 2 {
-		return true
+// sequenceEntries returns the AST entries of a list field, if available
+func sequenceEntries(service parser.Service, field string) []ast.Node {
+	if seq, ok := service.Field(field).(*ast.SequenceNode); ok {
+		return seq.Values
 	}
+	return nil
+}
 
-	keys := make([]string, 0, len(items))
-	for _, item := range items {
-		keys = append(keys, keyExtractor(item))
+// entryNode returns the i-th entry node or nil if positions are unavailable
+func entryNode(entries []ast.Node, i int) ast.Node {
+	if i < len(entries) {
+		return entries[i]
 	}
+	return nil
+}
 
-	sortedKeys := make([]string, len(keys))
-	copy(sortedKeys, keys)
-	// Sort case-insensitive
-	for i := 0; i < len(sortedKeys)-1; i++ {
-		for j := i + 1; j < len(sortedKeys); j++ {
-			if strings.ToLower(sortedKeys[i]) > strings.ToLower(sortedKeys[j]) {
-				sortedKeys[i], sortedKeys[j] = sortedKeys[j], sortedKeys[i]
+// fieldKeyPosition returns the position of a service field's key, falling back
+// to the service itself
+func fieldKeyPosition(service parser.Service, field string) (int, int) {
+	if service.Node != nil {
+		for _, value := range service.Node.Values {
+			if value.Key.String() == field {
+				return parser.Position(value.Key)
 			}
 		}
 	}
-
-	for i, key := range keys {
-		if key != sortedKeys[i] {
-			return false
-		}
-	}
-
-	return true
+	return service.Line, service.Column
 }
 
-// isMapAlphabetized checks if map keys are alphabetized
-func isMapAlphabetized(m map[string]interface{}) bool {
-	if len(m) < 2 {
-		return true
-	}
-
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-
-	sortedKeys := make([]string, len(keys))
-	copy(sortedKeys, keys)
-	// Sort case-insensitive
-	for i := 0; i < len(sortedKeys)-1; i++ {
-		for j := i + 1; j < len(sortedKeys); j++ {
-			if strings.ToLower(sortedKeys[i]) > strings.ToLower(sortedKeys[j]) {
-				sortedKeys[i], sortedKeys[j] = sortedKeys[j], sortedKeys[i]
-			}
-		}
+// isAlphabetized checks if a slice is alphabetized by the given key
+// extractor, comparing keys in the given mode
+func isAlphabetized(items []interface{}, keyExtractor func(interface{}) string, mode string) bool {
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, keyExtractor(item))
 	}
+	return keysAlphabetized(keys, mode)
+}
 
-	for i, key := range keys {
-		if key != sortedKeys[i] {
+// keysAlphabetized checks that no key sorts before the one preceding it.
+// Keys comparing equal may appear in any order, as the fixer keeps them.
+func keysAlphabetized(keys []string, mode string) bool {
+	for i := 1; i < len(keys); i++ {
+		if config.CompareKeys(mode, keys[i-1], keys[i]) > 0 {
 			return false
 		}
 	}
-
 	return true
 }
 
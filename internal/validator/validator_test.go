@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/yourusername/compose-validator/internal/config"
@@ -36,7 +37,7 @@ func TestValidate_ValidFieldOrder(t *testing.T) {
 		},
 	)
 
-	violations := validateFieldOrder("web", service, cfg.FieldOrder, cfg)
+	violations := ValidateFieldOrder("web", service, cfg.FieldOrder, cfg)
 
 	if len(violations) != 0 {
 		t.Errorf("Expected 0 violations for valid field order, got %d: %v", len(violations), violations)
@@ -57,7 +58,7 @@ func TestValidate_InvalidFieldOrder(t *testing.T) {
 		},
 	)
 
-	violations := validateFieldOrder("web", service, cfg.FieldOrder, cfg)
+	violations := ValidateFieldOrder("web", service, cfg.FieldOrder, cfg)
 
 	if len(violations) != 2 {
 		t.Errorf("Expected 2 violations, got %d: %v", len(violations), violations)
@@ -86,7 +87,7 @@ func TestValidate_AlphabetizedEnvironment_List(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 0 {
 		t.Errorf("Expected 0 violations for alphabetized env vars, got %d: %v", len(violations), violations)
@@ -110,7 +111,7 @@ func TestValidate_UnalphabetizedEnvironment_List(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 1 {
 		t.Errorf("Expected 1 violation for unalphabetized env vars, got %d: %v", len(violations), violations)
@@ -122,10 +123,44 @@ func TestValidate_UnalphabetizedEnvironment_List(t *testing.T) {
 }
 
 func TestValidate_AlphabetizedEnvironment_Map(t *testing.T) {
-	// Skip this test because Go maps don't preserve insertion order,
-	// making it impossible to reliably check if a map was alphabetized.
-	// In practice, environment variables should use list format for ordering.
-	t.Skip("Skipping map alphabetization test - Go maps don't preserve order")
+	yaml := `services:
+  sorted:
+    environment:
+      AAA: 1
+      "BBB": 2
+      ccc: 3
+    labels:
+      com.example.a: x
+      com.example.b: y
+  unsorted:
+    environment:
+      ZZZ: 1
+      AAA: 2
+      MMM: 3
+    labels:
+      com.example.b: y
+      com.example.a: x
+`
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	services := file.GetServices()
+	cfg := config.NewDefaultConfig()
+
+	// Keys are checked in file order, so the result is the same every time
+	for i := 0; i < 20; i++ {
+		if violations := ValidateAlphabetization("sorted", services["sorted"], cfg); len(violations) != 0 {
+			t.Fatalf("Expected no violations for sorted mappings, got %v", violations)
+		}
+		fields := make([]string, 0)
+		for _, v := range ValidateAlphabetization("unsorted", services["unsorted"], cfg) {
+			fields = append(fields, v.Field)
+		}
+		if strings.Join(fields, ",") != "environment,labels" {
+			t.Fatalf("Expected violations for environment and labels, got %v", fields)
+		}
+	}
 }
 
 func TestValidate_AlphabetizedVolumes(t *testing.T) {
@@ -145,7 +180,7 @@ func TestValidate_AlphabetizedVolumes(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 0 {
 		t.Errorf("Expected 0 violations for alphabetized volumes, got %d: %v", len(violations), violations)
@@ -169,7 +204,7 @@ func TestValidate_UnalphabetizedVolumes(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 1 {
 		t.Errorf("Expected 1 violation for unalphabetized volumes, got %d: %v", len(violations), violations)
@@ -197,7 +232,7 @@ func TestValidate_AlphabetizedLabels(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 0 {
 		t.Errorf("Expected 0 violations for alphabetized labels, got %d: %v", len(violations), violations)
@@ -221,7 +256,7 @@ func TestValidate_UnalphabetizedLabels(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 1 {
 		t.Errorf("Expected 1 violation for unalphabetized labels, got %d: %v", len(violations), violations)
@@ -243,7 +278,7 @@ func TestValidate_StrictMode_ExtraField(t *testing.T) {
 		},
 	)
 
-	violations := validateFieldOrder("web", service, cfg.FieldOrder, cfg)
+	violations := ValidateFieldOrder("web", service, cfg.FieldOrder, cfg)
 
 	if len(violations) != 1 {
 		t.Errorf("Expected 1 violation for extra field in strict mode, got %d: %v", len(violations), violations)
@@ -268,7 +303,7 @@ func TestValidate_NonStrictMode_ExtraField(t *testing.T) {
 		},
 	)
 
-	violations := validateFieldOrder("web", service, cfg.FieldOrder, cfg)
+	violations := ValidateFieldOrder("web", service, cfg.FieldOrder, cfg)
 
 	// Should not report violations for extra fields in non-strict mode
 	for _, v := range violations {
@@ -278,6 +313,52 @@ func TestValidate_NonStrictMode_ExtraField(t *testing.T) {
 	}
 }
 
+func TestValidate_FieldOrderWildcards(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.FieldOrder = []string{"image", "*", "environment", "x-*"}
+
+	fields := []string{"image", "restart", "depends_on", "environment", "x-note"}
+	service := createService("web", fields, map[string]interface{}{
+		"image":       "nginx",
+		"restart":     "always",
+		"depends_on":  []interface{}{"db"},
+		"environment": []interface{}{},
+		"x-note":      "hi",
+	})
+
+	// Unlisted fields keep their original order by default
+	if violations := ValidateFieldOrder("web", service, cfg.FieldOrder, cfg); len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v", violations)
+	}
+
+	cfg.UnlistedOrder = "alphabetical"
+	violations := ValidateFieldOrder("web", service, cfg.FieldOrder, cfg)
+	if len(violations) != 2 || violations[0].Field != "restart" || violations[0].Expected != "depends_on" {
+		t.Errorf("Expected restart and depends_on to be out of order, got %v", violations)
+	}
+
+	// An extension field before the placeholder is out of order
+	service.FieldOrder = []string{"image", "x-note", "restart", "depends_on", "environment"}
+	cfg.UnlistedOrder = "original"
+	violations = ValidateFieldOrder("web", service, cfg.FieldOrder, cfg)
+	if len(violations) == 0 || violations[0].Field != "x-note" {
+		t.Errorf("Expected x-note to be out of order, got %v", violations)
+	}
+
+	// The placeholder positions fields but does not list them in strict mode
+	cfg.Strict = true
+	violations = ValidateFieldOrder("web", service, cfg.FieldOrder, cfg)
+	strict := 0
+	for _, v := range violations {
+		if v.Message == "field 'restart' is not allowed in strict mode" || v.Message == "field 'depends_on' is not allowed in strict mode" {
+			strict++
+		}
+	}
+	if strict != 2 {
+		t.Errorf("Expected restart and depends_on to be reported in strict mode, got %v", violations)
+	}
+}
+
 func TestValidate_CaseInsensitiveAlphabetization(t *testing.T) {
 	cfg := config.NewDefaultConfig()
 
@@ -296,7 +377,7 @@ func TestValidate_CaseInsensitiveAlphabetization(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 0 {
 		t.Errorf("Expected 0 violations for case-insensitive alphabetization, got %d: %v", len(violations), violations)
@@ -319,7 +400,7 @@ func TestValidate_UnalphabetizedCaseInsensitive(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 1 {
 		t.Errorf("Expected 1 violation for unalphabetized case-insensitive env vars, got %d: %v", len(violations), violations)
@@ -339,7 +420,7 @@ func TestValidate_EmptyEnvironment(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 0 {
 		t.Errorf("Expected 0 violations for empty environment, got %d: %v", len(violations), violations)
@@ -359,7 +440,7 @@ func TestValidate_SingleItemEnvironment(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	if len(violations) != 0 {
 		t.Errorf("Expected 0 violations for single-item environment, got %d: %v", len(violations), violations)
@@ -383,7 +464,7 @@ func TestValidate_DisabledAlphabetization(t *testing.T) {
 		},
 	)
 
-	violations := validateAlphabetization("web", service, cfg)
+	violations := ValidateAlphabetization("web", service, cfg)
 
 	// Should not report violations when alphabetization is disabled
 	for _, v := range violations {
@@ -392,3 +473,96 @@ func TestValidate_DisabledAlphabetization(t *testing.T) {
 		}
 	}
 }
+
+func TestValidate_Severities(t *testing.T) {
+	yaml := `services:
+  web:
+    image: nginx
+    container_name: web
+  worker-1:
+    image: myapp
+    container_name: worker
+`
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.Severities = map[string]string{"order": "warning"}
+
+	result, err := Validate(file, cfg)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !result.Valid || len(result.Violations) != 4 || result.Errors() != 0 {
+		t.Fatalf("Expected four warnings and a valid result, got %+v", result)
+	}
+	for _, v := range result.Violations {
+		if v.Severity != "warning" {
+			t.Errorf("Expected warning severity, got %+v", v)
+		}
+	}
+
+	// An override turns the rule off for matching services and back to an
+	// error for others
+	cfg.Severities = nil
+	cfg.ServiceOverrides = map[string]config.ServiceOverride{
+		"worker-*": {Severities: map[string]string{"order": "off"}},
+	}
+	result, err = Validate(file, cfg)
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if result.Valid || result.Errors() != 2 {
+		t.Fatalf("Expected two errors, got %+v", result.Violations)
+	}
+	for _, v := range result.Violations {
+		if v.Service != "web" || v.Severity != "error" {
+			t.Errorf("Expected only errors for web, got %+v", v)
+		}
+	}
+}
+
+func TestValidate_AlphabetizationComparison(t *testing.T) {
+	yaml := `services:
+  web:
+    image: nginx
+    environment:
+      - api_key=c
+      - API_URL=d
+      - APP_2=b
+      - APP_10=a
+    labels:
+      - Zone=a
+      - app=b
+`
+	file, err := parser.ParseBytes("test.yml", []byte(yaml))
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+
+	tests := []struct {
+		environment string
+		labels      string
+		expected    []string
+	}{
+		{config.CompareCaseInsensitive, config.CompareCaseInsensitive, []string{"environment", "labels"}},
+		{config.CompareNatural, config.CompareByte, nil},
+		{config.CompareCaseSensitive, config.CompareCaseSensitive, []string{"environment", "labels"}},
+		{config.CompareByte, config.CompareByte, []string{"environment"}},
+	}
+
+	for _, test := range tests {
+		cfg := config.NewDefaultConfig()
+		cfg.Alphabetization.Comparison = config.ComparisonModes{Environment: test.environment, Labels: test.labels}
+
+		fields := make([]string, 0)
+		for _, v := range ValidateAlphabetization("web", file.GetServices()["web"], cfg) {
+			fields = append(fields, v.Field)
+		}
+		if strings.Join(fields, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("environment %s, labels %s: expected violations for %v, got %v", test.environment, test.labels, test.expected, fields)
+		}
+	}
+}